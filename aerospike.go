@@ -3,27 +3,75 @@ package aerospike
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/moby/moby/api/types/container"
 	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 const (
 	aerospikeServicePort     = "3000/tcp"
+	aerospikeInfoPort        = "3003/tcp"
+	aerospikeFabricPort      = "3001/tcp"
+	aerospikeHeartbeatPort   = "3002/tcp"
 	communityAerospikeImage  = "aerospike/aerospike-server:8.0"
 	enterpriseAerospikeImage = "aerospike/aerospike-server-enterprise:8.0"
+	// defaultNsupPeriod is the nsup-period, in seconds, set by WithTTLSupport.
+	defaultNsupPeriod = 10
 )
 
+// imageEntrypoint is the entrypoint of the official Aerospike server images.
+//
+//nolint:gochecknoglobals // treated as a constant
+var imageEntrypoint = []string{"/usr/bin/as-tini-static", "-r", "SIGUSR1", "-t", "SIGTERM", "--", "/entrypoint.sh"}
+
+// ErrInvalidArgument is returned when a Container method is called with an
+// argument it cannot use.
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// containerNamePattern matches the container names accepted by Docker.
+var containerNamePattern = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
 // Container represents a running Aerospike container.
 type Container struct {
 	testcontainers.Container
+
+	settings options
+	opts     []testcontainers.ContainerCustomizer
+	client   *sharedClient
 }
 
 // RunContainer creates an instance of the Aerospike container type.
 func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomizer) (*Container, error) {
+	genericContainerRequest, settings, err := newContainerRequest(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, genericContainerRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start Aerospike: %w", err)
+	}
+
+	return &Container{Container: container, settings: settings, opts: opts, client: &sharedClient{}}, nil
+}
+
+// newContainerRequest assembles the container request and package settings
+// from opts without touching Docker.
+func newContainerRequest(opts ...testcontainers.ContainerCustomizer) (testcontainers.GenericContainerRequest, options, error) {
 	containerRequest := testcontainers.ContainerRequest{
 		Image:        communityAerospikeImage,
-		ExposedPorts: []string{"3000/tcp"},
+		ExposedPorts: []string{aerospikeServicePort, aerospikeInfoPort},
+		Env:          stableLocaleEnv(),
 		WaitingFor:   newAerospikeWaitStrategy(),
 	}
 
@@ -32,27 +80,152 @@ func RunContainer(ctx context.Context, opts ...testcontainers.ContainerCustomize
 		Started:          true,
 	}
 
+	settings := defaultOptions()
 	for _, opt := range opts {
+		// Options are applied through the settings, which the steps below
+		// turn into the request; their Customize is for standalone use.
+		if apply, ok := opt.(Option); ok {
+			if err := apply(&settings); err != nil {
+				return genericContainerRequest, settings, fmt.Errorf("failed to apply option: %w", err)
+			}
+			continue
+		}
 		if err := opt.Customize(&genericContainerRequest); err != nil {
-			return nil, fmt.Errorf("failed to apply option: %w", err)
+			return genericContainerRequest, settings, fmt.Errorf("failed to apply option: %w", err)
 		}
 	}
 
-	container, err := testcontainers.GenericContainer(ctx, genericContainerRequest)
+	if (len(settings.roles) > 0 || len(settings.users) > 0) && settings.user == "" {
+		return genericContainerRequest, settings, fmt.Errorf("failed to apply option: %w: WithRole and WithUser require WithSecurity", ErrInvalidOption)
+	}
+	cfg, err := applyServerConfig(&genericContainerRequest, settings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start Aerospike: %w", err)
+		return genericContainerRequest, settings, fmt.Errorf("failed to render server config: %w", err)
+	}
+	if settings.exporter != nil {
+		settings.exporter.attach(&genericContainerRequest, settings)
+	}
+	if len(settings.setConfigs) > 0 {
+		attachSetConfigs(&genericContainerRequest, settings)
 	}
+	if len(settings.udfs) > 0 {
+		attachUDFs(&genericContainerRequest, settings)
+	}
+	if settings.servicePort != 0 {
+		for i, port := range genericContainerRequest.ExposedPorts {
+			if port == aerospikeServicePort {
+				genericContainerRequest.ExposedPorts[i] = settings.servicePortSpec()
+			}
+		}
+	}
+
+	// The built-in wait strategy checks the namespace set with WithNamespace.
+	// A custom config file may not define it, so the check is skipped then,
+	// and without the default namespace the first one defined is checked.
+	if strategy, ok := genericContainerRequest.WaitingFor.(aerospikeWaitStrategy); ok {
+		switch {
+		case settings.configFile != "":
+			strategy.namespace = ""
+		case settings.noDefaultNamespace:
+			strategy.namespace = cfg.namespaces()[0]
+		case genericContainerRequest.Env["NAMESPACE"] != "":
+			strategy.namespace = genericContainerRequest.Env["NAMESPACE"]
+		}
+		strategy.port = settings.servicePortSpec()
+		genericContainerRequest.WaitingFor = strategy
+	}
+
+	for _, modify := range settings.requestModifiers {
+		modify(&genericContainerRequest.ContainerRequest)
+	}
+	settings.image = genericContainerRequest.Image
+	settings.networks = genericContainerRequest.Networks
+	settings.networkAliases = genericContainerRequest.NetworkAliases
 
-	return &Container{Container: container}, nil
+	return genericContainerRequest, settings, nil
 }
 
-// ServicePort returns the port on which the Aerospike container is listening.
+// ServicePort returns the mapped port of the server's service port, 3000 or
+// the port set with WithServicePort. It returns ErrContainerNotRunning when the
+// container is not running, as its ports are not mapped then.
 func (c Container) ServicePort(ctx context.Context) (int, error) {
-	port, err := c.MappedPort(ctx, aerospikeServicePort)
+	return c.mappedPort(ctx, c.settings.servicePortSpec())
+}
+
+// InfoPort returns the mapped port of the server's info port, 3003, which
+// monitoring tools use for plain-text info requests. It is not the fabric port,
+// 3001, which only carries traffic between nodes.
+func (c Container) InfoPort(ctx context.Context) (int, error) {
+	return c.mappedPort(ctx, aerospikeInfoPort)
+}
+
+// FabricPort returns the mapped port of the server's fabric port, 3001, which
+// nodes use to replicate and migrate data. It is only mapped when the
+// container was started with WithFabricPort.
+func (c Container) FabricPort(ctx context.Context) (int, error) {
+	return c.mappedPort(ctx, aerospikeFabricPort)
+}
+
+// HeartbeatPort returns the mapped port of the server's mesh heartbeat port,
+// 3002. It is only mapped when the container was started with
+// WithHeartbeatPort.
+func (c Container) HeartbeatPort(ctx context.Context) (int, error) {
+	return c.mappedPort(ctx, aerospikeHeartbeatPort)
+}
+
+// HostPort returns the host and mapped service port at which the server is
+// reachable from the test process. The host is resolved by testcontainers, so
+// it honors DOCKER_HOST and the TESTCONTAINERS_HOST_OVERRIDE setting.
+func (c Container) HostPort(ctx context.Context) (string, int, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch host: %w", err)
+	}
+	port, err := c.ServicePort(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch port: %w", err)
+	}
+
+	return host, port, nil
+}
+
+// ConnectionString returns the "host:port" endpoint of the server, as returned
+// by HostPort, for code under test that builds its own client.
+func (c Container) ConnectionString(ctx context.Context) (string, error) {
+	host, port, err := c.HostPort(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+// mappedPort returns the host port that port, such as "3000/tcp", is mapped
+// to, or ErrContainerNotRunning when the container is not running.
+func (c Container) mappedPort(ctx context.Context, port string) (int, error) {
+	if err := c.requireRunning(ctx); err != nil {
+		return 0, err
+	}
+
+	mapped, err := c.MappedPort(ctx, port)
 	if err != nil {
 		return 0, err
 	}
-	return int(port.Num()), nil
+	return int(mapped.Num()), nil
+}
+
+// requireRunning returns ErrContainerNotRunning when the container is not
+// running.
+func (c Container) requireRunning(ctx context.Context) error {
+	state, err := c.State(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if !state.Running {
+		return fmt.Errorf("%w: container is %s", ErrContainerNotRunning, state.Status)
+	}
+
+	return nil
 }
 
 // WithImage sets the image for the Aerospike container.
@@ -65,18 +238,215 @@ func WithEnterpriseEdition() testcontainers.CustomizeRequestOption {
 	return WithImage(enterpriseAerospikeImage)
 }
 
-// WithPort sets the port for the Aerospike container.
+// WithPort sets the port for the Aerospike container. It replaces the exposed
+// service port only, so the info port stays exposed for InfoPort.
 func WithPort(port string) testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
-		req.ExposedPorts = []string{port}
+		for i, exposed := range req.ExposedPorts {
+			if exposed == aerospikeServicePort {
+				req.ExposedPorts[i] = port
+				return nil
+			}
+		}
+		req.ExposedPorts = append([]string{port}, req.ExposedPorts...)
+		return nil
+	}
+}
+
+// WithWaitStrategy replaces the built-in readiness check with strategy. This is
+// an escape hatch for unusual images or startup sequences: the default
+// strategy's Aerospike-specific checks, such as the probe that waits for the
+// namespace to accept writes, no longer run, so RunContainer may return before
+// the server is able to serve requests.
+func WithWaitStrategy(strategy wait.Strategy) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.WaitingFor = strategy
+		return nil
+	}
+}
+
+// WithStartupTimeout sets how long RunContainer waits for the server to
+// become ready, 60s by default. The timeout covers every readiness probe of
+// the built-in wait strategy, so it cannot be combined with WithWaitStrategy;
+// give the replacement strategy its own timeout instead.
+func WithStartupTimeout(d time.Duration) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: startup timeout must be positive, got %s", ErrInvalidOption, d)
+		}
+		strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+		if !ok {
+			return fmt.Errorf("%w: WithStartupTimeout only applies to the built-in wait strategy", ErrInvalidOption)
+		}
+		strategy.timeout = d
+		req.WaitingFor = strategy
+
+		return nil
+	}
+}
+
+// WithStarted controls whether RunContainer starts the container. With false
+// it only creates it, so the caller can prepare it, for example by connecting
+// it to another network, before starting it with Start. The wait strategy
+// runs whenever the container starts, so Start returns once the server is
+// ready, just as RunContainer does for a started container. Until then the
+// port accessors and Client return ErrContainerNotRunning.
+func WithStarted(started bool) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Started = started
 		return nil
 	}
 }
 
+// WithContainerName sets the Docker container name, making it easy to tell
+// several Aerospike containers apart in docker ps and logs. Docker requires
+// names to be unique, so starting a second container with the same name fails.
+func WithContainerName(name string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if !containerNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: invalid container name %q", ErrInvalidOption, name)
+		}
+		req.Name = name
+
+		return nil
+	}
+}
+
+// WithReuse names the container name, like WithContainerName, and reuses it
+// across RunContainer calls: a call that finds a container with that name,
+// such as one started by an earlier test package, attaches to it instead of
+// starting a new server, which makes large suites much faster.
+//
+// A reused container keeps its data, so tests that share one must not depend
+// on starting empty; call Truncate on the sets they use before or after each
+// test. They must also pass the same options, as the configuration of the
+// container found is kept whatever the options of the later calls, and they
+// must leave it running: Terminate removes it for every test using it. The
+// Testcontainers reaper removes it once the session that created it ends,
+// unless the reaper is disabled.
+func WithReuse(name string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if err := WithContainerName(name)(req); err != nil {
+			return err
+		}
+		req.Reuse = true
+
+		return nil
+	}
+}
+
+// WithNetwork attaches the container to the existing Docker network
+// networkName, where other containers, such as the application under test,
+// reach the server as any of aliases on port 3000. Aliases are only supported
+// on user-defined networks, so they are rejected for the default "bridge"
+// network. The option can be given more than once to join several networks,
+// and a Cluster's nodes can join another network this way as well as their
+// own.
+func WithNetwork(networkName string, aliases ...string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if networkName = strings.TrimSpace(networkName); networkName == "" {
+			return fmt.Errorf("%w: network name is empty", ErrInvalidOption)
+		}
+		for _, alias := range aliases {
+			if strings.TrimSpace(alias) == "" {
+				return fmt.Errorf("%w: empty alias for network %q", ErrInvalidOption, networkName)
+			}
+		}
+		if networkName == "bridge" {
+			if len(aliases) > 0 {
+				return fmt.Errorf("%w: network aliases require a user-defined network, not %q", ErrInvalidOption, networkName)
+			}
+			return network.WithBridgeNetwork()(req)
+		}
+
+		return network.WithNetworkName(aliases, networkName)(req)
+	}
+}
+
+// WithMemoryLimit caps the memory of the container at bytes, so tests can
+// reproduce the server running out of memory. Docker must support the memory
+// cgroup controller, which rootless setups and some CI runners lack; the
+// container then fails to start. The namespaces still size themselves from
+// their own configuration, so keep their sizes below the limit.
+func WithMemoryLimit(bytes int64) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if bytes <= 0 {
+			return fmt.Errorf("%w: memory limit must be positive, got %d", ErrInvalidOption, bytes)
+		}
+		addHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.Memory = bytes
+		})
+		return nil
+	}
+}
+
+// WithCPUs limits the container to n CPUs, such as 0.5 for half a CPU, so
+// tests can reproduce a throttled server. Docker must support the cpu cgroup
+// controller, which rootless setups and some CI runners lack; the container
+// then fails to start.
+func WithCPUs(n float64) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if n <= 0 || math.IsNaN(n) || math.IsInf(n, 1) {
+			return fmt.Errorf("%w: CPUs must be positive, got %v", ErrInvalidOption, n)
+		}
+		nanoCPUs := int64(n * 1e9)
+		addHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+			hostConfig.NanoCPUs = nanoCPUs
+		})
+		return nil
+	}
+}
+
+// WithLabels adds labels to the container, merging them with any labels that
+// are already set. Labels can be used to filter or reap containers.
+func WithLabels(labels map[string]string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithLabels(labels)
+}
+
+// WithEntrypointWrapper runs script with /bin/sh inside the container before
+// the Aerospike server starts, for setup that a post-start hook would be too
+// late for, such as raising ulimits or generating files the server reads at
+// boot.
+//
+// The script runs with "set -e", so a failing command stops the container.
+// Once it returns, the wrapper execs the official image entrypoint with the
+// original arguments; the script must therefore return normally rather than
+// exec or exit on its own. Images with a different entrypoint need
+// testcontainers.WithEntrypoint instead.
+func WithEntrypointWrapper(script string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if strings.TrimSpace(script) == "" {
+			return fmt.Errorf("%w: entrypoint wrapper script is empty", ErrInvalidOption)
+		}
+
+		wrapper := "set -e\n" + script + "\nexec " + strings.Join(imageEntrypoint, " ") + ` "$@"`
+		req.Entrypoint = []string{"/bin/sh", "-c", wrapper, "sh"}
+		// Overriding the entrypoint drops the image's default command.
+		if len(req.Cmd) == 0 {
+			req.Cmd = []string{"asd"}
+		}
+
+		return nil
+	}
+}
+
+// WithStableLocale runs the server and the tools exec'd in the container with
+// the C locale, so asinfo and asadm output parses the same regardless of the
+// image or host locale. This is the default; the option exists to restore it
+// after other options replace the environment.
+func WithStableLocale() testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(stableLocaleEnv())
+}
+
 // WithNamespace sets the default namespace that is created when Aerospike
-// starts. By default, this is set to "test".
+// starts. By default, this is set to "test". Surrounding whitespace is
+// trimmed, and a name that is empty after trimming is rejected.
 func WithNamespace(namespace string) testcontainers.CustomizeRequestOption {
 	return func(req *testcontainers.GenericContainerRequest) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
 		if req.Env == nil {
 			req.Env = make(map[string]string)
 		}
@@ -101,20 +471,115 @@ func WithLogLevel(logLevel string) testcontainers.CustomizeRequestOption {
 // WithTTLSupport enables TTL (time-to-live) support for records by setting nsup-period.
 // This is required for records with explicit TTL values to expire properly.
 // The namespace parameter specifies which namespace to configure (default: "test").
-func WithTTLSupport(namespace string) testcontainers.CustomizeRequestOption {
+// Expired records are removed every 10 seconds; use WithNsupPeriod for another
+// interval.
+func WithTTLSupport(namespace string) Option {
+	return WithNsupPeriod(namespace, 0)
+}
+
+// WithNsupPeriod is like WithTTLSupport but removes expired records every
+// seconds seconds. Zero selects the WithTTLSupport default of 10. Very small
+// periods make expiry tests faster but keep the namespace supervisor busy,
+// which costs CPU on namespaces with many records.
+func WithNsupPeriod(namespace string, seconds int) Option {
+	namespace = strings.TrimSpace(namespace)
 	if namespace == "" {
-		namespace = "test"
+		namespace = defaultNamespace
 	}
-	return func(req *testcontainers.GenericContainerRequest) error {
-		req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
-			PostStarts: []testcontainers.ContainerHook{
-				func(ctx context.Context, c testcontainers.Container) error {
-					cmd := []string{"asinfo", "-v", fmt.Sprintf("set-config:context=namespace;id=%s;nsup-period=10", namespace)}
-					_, _, err := c.Exec(ctx, cmd)
-					return err
-				},
-			},
-		})
+	if seconds == 0 {
+		seconds = defaultNsupPeriod
+	}
+	return func(o *options) error {
+		if seconds < 0 {
+			return fmt.Errorf("%w: nsup period must be positive, got %d", ErrInvalidOption, seconds)
+		}
+		return WithSetConfig("namespace", namespace, "nsup-period", strconv.Itoa(seconds))(o)
+	}
+}
+
+// WithSetConfig changes a dynamic configuration parameter once the server is
+// ready by issuing set-config:context=<configContext>;id=<id>;<param>=<value>,
+// for tunables no dedicated option covers. id names the namespace or other
+// subcontext and may be empty for the service context. The command runs after
+// security has been set up, with the same credentials and service port as the
+// Container helpers. Startup fails with ErrConfigRejected when the server
+// refuses the change.
+func WithSetConfig(configContext, id, param, value string) Option {
+	return func(o *options) error {
+		if configContext == "" || param == "" {
+			return fmt.Errorf("%w: set-config context and parameter must not be empty", ErrInvalidOption)
+		}
+		if id == "" && configContext != "service" {
+			return fmt.Errorf("%w: set-config context %q requires an id", ErrInvalidOption, configContext)
+		}
+		o.setConfigs = append(o.setConfigs, setConfigSpec{configContext: configContext, id: id, param: param, value: value})
+
 		return nil
 	}
 }
+
+// setConfigSpec is a dynamic configuration change set with WithSetConfig.
+type setConfigSpec struct {
+	configContext string
+	id            string
+	param         string
+	value         string
+}
+
+// attachSetConfigs adds the hook that applies the changes set with
+// WithSetConfig once the server is ready. It is attached after the hooks of
+// the other options, so the changes run with the credentials provisioned by
+// WithSecurity.
+func attachSetConfigs(req *testcontainers.GenericContainerRequest, settings options) {
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostReadies: []testcontainers.ContainerHook{setConfigsHook(settings)},
+	})
+}
+
+// setConfigsHook returns a hook that applies the changes set with
+// WithSetConfig, in order, with settings.
+func setConfigsHook(settings options) testcontainers.ContainerHook {
+	return func(ctx context.Context, c testcontainers.Container) error {
+		for _, change := range settings.setConfigs {
+			if err := setConfig(ctx, c, settings, change.configContext, change.id, change.param, change.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// normalizeNamespace trims surrounding whitespace from a namespace name and
+// rejects names that are empty afterwards, wrapping invalid in the error.
+// Stray whitespace is easy to pick up from test fixtures and otherwise leads
+// to confusing startup or lookup failures.
+func normalizeNamespace(namespace string, invalid error) (string, error) {
+	namespace = strings.TrimSpace(namespace)
+	if namespace == "" {
+		return "", fmt.Errorf("%w: namespace is empty", invalid)
+	}
+
+	return namespace, nil
+}
+
+// stableLocaleEnv returns the environment that pins the container to the C
+// locale, which keeps numbers in tool output free of locale-specific
+// separators.
+func stableLocaleEnv() map[string]string {
+	return map[string]string{
+		"LANG":   "C",
+		"LC_ALL": "C",
+	}
+}
+
+// addHostConfigModifier adds modify to the host config modifier of req, after
+// any modifier set before, so options can each change the host config.
+func addHostConfigModifier(req *testcontainers.GenericContainerRequest, modify func(*container.HostConfig)) {
+	previous := req.HostConfigModifier
+	req.HostConfigModifier = func(hostConfig *container.HostConfig) {
+		if previous != nil {
+			previous(hostConfig)
+		}
+		modify(hostConfig)
+	}
+}
@@ -1,16 +1,25 @@
 package aerospike
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"math"
+	"net"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	mobycontainer "github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 // Unit tests for option functions (no Docker required)
@@ -25,6 +34,25 @@ func TestWithNamespaceOption(t *testing.T) {
 	assert.Equal(t, "custom-namespace", req.Env["NAMESPACE"])
 }
 
+func TestWithNamespaceOptionTrimsWhitespace(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{}
+
+	err := WithNamespace(" \tcustom-namespace\n").Customize(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "custom-namespace", req.Env["NAMESPACE"])
+}
+
+func TestWithNamespaceOptionRejectsBlank(t *testing.T) {
+	for _, namespace := range []string{"", " ", "\t\n"} {
+		req := &testcontainers.GenericContainerRequest{}
+
+		err := WithNamespace(namespace).Customize(req)
+		require.ErrorIs(t, err, ErrInvalidOption)
+		assert.NotContains(t, req.Env, "NAMESPACE")
+	}
+}
+
 func TestWithLogLevelOption(t *testing.T) {
 	req := &testcontainers.GenericContainerRequest{}
 	opt := WithLogLevel("debug")
@@ -79,6 +107,288 @@ func TestWithPortOptionOverridesExisting(t *testing.T) {
 	assert.Equal(t, []string{"4000/tcp"}, req.ExposedPorts)
 }
 
+func TestWithPortKeepsInfoPort(t *testing.T) {
+	req, _, err := newContainerRequest(WithPort("4000/tcp"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"4000/tcp", aerospikeInfoPort}, req.ExposedPorts)
+}
+
+func TestInfoPortIsExposedByDefault(t *testing.T) {
+	req, _, err := newContainerRequest()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{aerospikeServicePort, aerospikeInfoPort}, req.ExposedPorts)
+
+	conf := renderedConfig(t, WithNamespaces("cache"))
+	assert.Contains(t, conf, "\tinfo {\n\t\taddress any\n\t\tport 3003\n\t}\n")
+}
+
+func TestWithWaitStrategyOption(t *testing.T) {
+	strategy := wait.ForListeningPort("3000/tcp")
+
+	req, _, err := newContainerRequest(WithWaitStrategy(strategy))
+	require.NoError(t, err)
+
+	assert.Same(t, strategy, req.WaitingFor)
+}
+
+// recordingStrategy is a wait.Strategy that records that it ran before
+// delegating to strategy.
+type recordingStrategy struct {
+	wait.Strategy
+
+	ran *bool
+}
+
+func (s recordingStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
+	*s.ran = true
+	return s.Strategy.WaitUntilReady(ctx, target)
+}
+
+func TestWithWaitStrategyIsHonored(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	var ran bool
+	strategy := recordingStrategy{Strategy: wait.ForListeningPort(aerospikeServicePort), ran: &ran}
+
+	container := startContainer(ctx, t, WithWaitStrategy(strategy))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	assert.True(t, ran, "the custom wait strategy did not run")
+}
+
+func TestWithStartupTimeoutOption(t *testing.T) {
+	req, _, err := newContainerRequest(WithStartupTimeout(3 * time.Minute))
+	require.NoError(t, err)
+
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Minute, strategy.timeout)
+
+	_, _, err = newContainerRequest(WithStartupTimeout(0))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithWaitStrategy(wait.ForLog("ready")), WithStartupTimeout(time.Minute))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithStartupTimeoutComposesWithSecurity(t *testing.T) {
+	req, _, err := newContainerRequest(WithEnterpriseEdition(), WithStartupTimeout(3*time.Minute), WithSecurity("tester", "secret"))
+	require.NoError(t, err)
+
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, 3*time.Minute, strategy.timeout)
+	assert.Equal(t, "tester", strategy.user)
+	assert.Equal(t, defaultAdminUser, strategy.fallbackUser)
+}
+
+func TestWithContainerNameOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "simple", input: "aerospike-1"},
+		{name: "dots and underscores", input: "suite_a.aerospike"},
+		{name: "leading slash", input: "/aerospike"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "single character", input: "a", wantErr: true},
+		{name: "leading dash", input: "-aerospike", wantErr: true},
+		{name: "space", input: "aero spike", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &testcontainers.GenericContainerRequest{}
+
+			err := WithContainerName(tt.input).Customize(req)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidOption)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, req.Name)
+		})
+	}
+}
+
+func TestWithReuseOption(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{}
+	require.NoError(t, WithReuse("aerospike-shared").Customize(req))
+	assert.Equal(t, "aerospike-shared", req.Name)
+	assert.True(t, req.Reuse)
+
+	require.ErrorIs(t, WithReuse("").Customize(&testcontainers.GenericContainerRequest{}), ErrInvalidOption)
+}
+
+func TestWithReuseAttachesToRunningContainer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+	name := "aerospike-reuse-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	first := startContainer(ctx, t, WithReuse(name))
+	t.Cleanup(func() {
+		require.NoErrorf(t, first.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+	require.NoError(t, first.Seed(ctx, "test", "reuse", []SeedRecord{{Key: "key", Bins: aerospike.BinMap{"bin": "value"}}}))
+
+	second, err := RunContainer(ctx, WithReuse(name))
+	require.NoError(t, err)
+	// Terminating second would remove the container first uses.
+	t.Cleanup(second.client.close)
+	assert.Equal(t, first.GetContainerID(), second.GetContainerID())
+
+	count, err := second.QueryCount(ctx, "test", "reuse", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, second.Truncate(ctx, "test", "reuse"))
+}
+
+func TestWithNetworkOption(t *testing.T) {
+	req, _, err := newContainerRequest(WithNetwork("app", "aerospike", "db"), WithNetwork("bridge"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"app", "bridge"}, req.Networks)
+	assert.Equal(t, map[string][]string{"app": {"aerospike", "db"}}, req.NetworkAliases)
+
+	for _, opt := range []testcontainers.CustomizeRequestOption{
+		WithNetwork(" "),
+		WithNetwork("app", ""),
+		WithNetwork("bridge", "aerospike"),
+	} {
+		_, _, err := newContainerRequest(opt)
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestWithNetworkAlias(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, nw.Remove(ctx))
+	})
+
+	server := startContainer(ctx, t, WithNetwork(nw.Name, "aerospike"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, server.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	// A second container on the network reaches the server by its alias.
+	app := startContainer(ctx, t, WithNetwork(nw.Name))
+	t.Cleanup(func() {
+		require.NoErrorf(t, app.Terminate(ctx), "failed to terminate application container")
+	})
+
+	result, err := runExec(ctx, app.Container, []string{"asinfo", "-h", "aerospike", "-v", "status"})
+	require.NoError(t, err)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "ok", strings.TrimSpace(result.stdout))
+}
+
+func TestWithResourceLimits(t *testing.T) {
+	req, _, err := newContainerRequest(WithMemoryLimit(512<<20), WithCPUs(1.5))
+	require.NoError(t, err)
+	require.NotNil(t, req.HostConfigModifier)
+
+	hostConfig := &mobycontainer.HostConfig{}
+	req.HostConfigModifier(hostConfig)
+	assert.Equal(t, int64(512<<20), hostConfig.Memory)
+	assert.Equal(t, int64(1_500_000_000), hostConfig.NanoCPUs)
+
+	for _, opt := range []testcontainers.CustomizeRequestOption{
+		WithMemoryLimit(0),
+		WithMemoryLimit(-1),
+		WithCPUs(0),
+		WithCPUs(-0.5),
+		WithCPUs(math.NaN()),
+		WithCPUs(math.Inf(1)),
+	} {
+		_, _, err := newContainerRequest(opt)
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestWithResourceLimitsAreApplied(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithMemoryLimit(1<<30), WithCPUs(1))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	inspect, err := container.Inspect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<30), inspect.HostConfig.Memory)
+	assert.Equal(t, int64(1_000_000_000), inspect.HostConfig.NanoCPUs)
+}
+
+func TestWithLabelsOption(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Labels: map[string]string{"existing": "label"},
+		},
+	}
+
+	err := WithLabels(map[string]string{"suite": "payments"}).Customize(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"existing": "label", "suite": "payments"}, req.Labels)
+}
+
+func TestWithEntrypointWrapperOption(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{}
+
+	err := WithEntrypointWrapper("ulimit -n 100000").Customize(req)
+	require.NoError(t, err)
+
+	require.Len(t, req.Entrypoint, 4)
+	assert.Equal(t, []string{"/bin/sh", "-c"}, req.Entrypoint[:2])
+	assert.Equal(t, "set -e\nulimit -n 100000\nexec /usr/bin/as-tini-static -r SIGUSR1 -t SIGTERM -- /entrypoint.sh \"$@\"", req.Entrypoint[2])
+	assert.Equal(t, []string{"asd"}, req.Cmd)
+}
+
+func TestWithEntrypointWrapperOptionRejectsEmptyScript(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{}
+
+	err := WithEntrypointWrapper(" \n\t").Customize(req)
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestStableLocaleIsDefault(t *testing.T) {
+	req, _, err := newContainerRequest()
+	require.NoError(t, err)
+
+	assert.Equal(t, "C", req.Env["LC_ALL"])
+	assert.Equal(t, "C", req.Env["LANG"])
+}
+
+func TestWithStableLocaleOption(t *testing.T) {
+	req := &testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Env: map[string]string{"LC_ALL": "de_DE.UTF-8", "NAMESPACE": "test"},
+		},
+	}
+
+	err := WithStableLocale().Customize(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"LANG": "C", "LC_ALL": "C", "NAMESPACE": "test"}, req.Env)
+}
+
 func TestWithTTLSupportOption(t *testing.T) {
 	req := &testcontainers.GenericContainerRequest{}
 	opt := WithTTLSupport("test")
@@ -100,6 +410,143 @@ func TestWithTTLSupportOptionDefaultNamespace(t *testing.T) {
 	assert.Len(t, req.LifecycleHooks, 1)
 }
 
+func TestWithNsupPeriodOption(t *testing.T) {
+	req, _, err := newContainerRequest(WithNsupPeriod("test", 1))
+	require.NoError(t, err)
+	assert.Len(t, req.LifecycleHooks, 1)
+
+	_, _, err = newContainerRequest(WithNsupPeriod("test", -1))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithNsupPeriodOptionCommand(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 1, want: "set-config:context=namespace;id=test;nsup-period=1"},
+		{seconds: 0, want: "set-config:context=namespace;id=test;nsup-period=10"},
+	}
+
+	for _, tt := range tests {
+		req, _, err := newContainerRequest(WithNsupPeriod(" ", tt.seconds))
+		require.NoError(t, err)
+
+		var got string
+		c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			got = cmd[len(cmd)-1]
+			return 0, "ok", nil
+		}}
+		require.NoError(t, req.LifecycleHooks[0].PostReadies[0](context.Background(), c))
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestWithSetConfigOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "namespace",
+			opt:  WithSetConfig("namespace", "test", "stop-writes-sys-memory-pct", "80"),
+			want: "set-config:context=namespace;id=test;stop-writes-sys-memory-pct=80",
+		},
+		{
+			name: "service without id",
+			opt:  WithSetConfig("service", "", "proto-fd-idle-ms", "70000"),
+			want: "set-config:context=service;proto-fd-idle-ms=70000",
+		},
+		{name: "missing id", opt: WithSetConfig("namespace", "", "nsup-period", "1"), wantErr: ErrInvalidOption},
+		{name: "missing param", opt: WithSetConfig("service", "", "", "1"), wantErr: ErrInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _, err := newContainerRequest(tt.opt)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var got string
+			c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+				got = cmd[len(cmd)-1]
+				return 0, "ok", nil
+			}}
+			require.NoError(t, req.LifecycleHooks[0].PostReadies[0](context.Background(), c))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithSetConfigUsesContainerSettings(t *testing.T) {
+	req, _, err := newContainerRequest(
+		WithEnterpriseEdition(),
+		WithSecurity("ops", "secret"),
+		WithServicePort(4000),
+		WithSetConfig("service", "", "proto-fd-idle-ms", "70000"),
+	)
+	require.NoError(t, err)
+
+	// The security hook comes first, so the change runs once ops exists.
+	hooks := req.LifecycleHooks[len(req.LifecycleHooks)-1].PostReadies
+	require.Len(t, hooks, 1)
+
+	var got []string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		got = cmd
+		return 0, "ok", nil
+	}}
+	require.NoError(t, hooks[0](context.Background(), c))
+	assert.Equal(t, []string{
+		"asinfo", "-p", "4000", "-U", "ops", "-P", "secret",
+		"-v", "set-config:context=service;proto-fd-idle-ms=70000",
+	}, got)
+}
+
+func TestWithSetConfigCustomizeOnItsOwn(t *testing.T) {
+	// Passed straight to testcontainers, the change runs as soon as the
+	// container has started, with the default settings.
+	req := &testcontainers.GenericContainerRequest{}
+	require.NoError(t, WithSetConfig("service", "", "proto-fd-idle-ms", "70000").Customize(req))
+	require.Len(t, req.LifecycleHooks, 1)
+	require.Len(t, req.LifecycleHooks[0].PostStarts, 1)
+
+	var got []string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		got = cmd
+		return 0, "ok", nil
+	}}
+	require.NoError(t, req.LifecycleHooks[0].PostStarts[0](context.Background(), c))
+	assert.Equal(t, []string{"asinfo", "-v", "set-config:context=service;proto-fd-idle-ms=70000"}, got)
+
+	err := WithSetConfig("namespace", "", "nsup-period", "1").Customize(&testcontainers.GenericContainerRequest{})
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithSetConfigReportsRejection(t *testing.T) {
+	req, _, err := newContainerRequest(WithSetConfig("service", "", "no-such-param", "1"))
+	require.NoError(t, err)
+
+	c := &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+		return 0, "error", nil
+	}}
+	err = req.LifecycleHooks[0].PostReadies[0](context.Background(), c)
+	require.ErrorIs(t, err, ErrConfigRejected)
+	assert.Contains(t, err.Error(), "no-such-param=1")
+}
+
+func TestRunContainerRejectsInvalidOption(t *testing.T) {
+	// Options are validated before any container is requested, so this does
+	// not need Docker.
+	_, err := RunContainer(context.Background(), WithInfoTimeout(-time.Second))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
 // skipIfDockerNotAvailable skips the test if Docker daemon is not available.
 func skipIfDockerNotAvailable(t *testing.T) {
 	t.Helper()
@@ -218,6 +665,10 @@ func TestPut(t *testing.T) {
 
 	client := newAerospikeClient(t, host, port)
 
+	namespaces, err := container.ListNamespaces(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"namespace"}, namespaces)
+
 	key, err := aerospike.NewKey("namespace", "set", "key")
 	require.NoErrorf(t, err, "failed to create Aerospike key")
 	bin := aerospike.NewBin("bin", "value")
@@ -226,6 +677,98 @@ func TestPut(t *testing.T) {
 	require.NoErrorf(t, err, "failed to create Aerospike record")
 }
 
+func TestServicePortRequiresRunningContainer(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{state: &mobycontainer.State{Status: mobycontainer.StateExited}},
+		settings:  defaultOptions(),
+	}
+
+	_, err := c.ServicePort(context.Background())
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+}
+
+func TestServicePortOfStoppedContainer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	_, err := container.ServicePort(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, container.Stop(ctx, nil))
+
+	_, err = container.ServicePort(ctx)
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+}
+
+func TestWithStartedOption(t *testing.T) {
+	req, _, err := newContainerRequest()
+	require.NoError(t, err)
+	assert.True(t, req.Started)
+
+	req, _, err = newContainerRequest(WithStarted(false))
+	require.NoError(t, err)
+	assert.False(t, req.Started)
+}
+
+func TestWithStartedDefersStart(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithStarted(false))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	_, err := container.ServicePort(ctx)
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+
+	// Start runs the wait strategy, so the server accepts writes straight away.
+	require.NoError(t, container.Start(ctx))
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "deferred", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
+
+func TestInfoPort(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	// WithPort replaces the service port only, so the info port is still
+	// mapped.
+	container := startContainer(ctx, t, WithPort(aerospikeServicePort))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.InfoPort(ctx)
+	require.NoError(t, err)
+
+	// The info port answers plain-text requests, one per line.
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), 5*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = conn.Write([]byte("build\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d+\.\d+`, strings.TrimSpace(line))
+}
+
 func TestWithImage(t *testing.T) {
 	skipIfDockerNotAvailable(t)
 
@@ -263,6 +806,10 @@ func TestWithLogLevel(t *testing.T) {
 
 	// newAerospikeClient fails the test if the client cannot connect.
 	newAerospikeClient(t, host, port)
+
+	logs, err := container.DrainLogs(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, logs, "service ready", "the server logs when it is ready to serve requests")
 }
 
 func TestWithTTLSupport(t *testing.T) {
@@ -303,6 +850,24 @@ func TestWithTTLSupport(t *testing.T) {
 	})
 }
 
+func TestWithEntrypointWrapper(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEntrypointWrapper("echo wrapped > /tmp/pre-start"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	exitCode, reader, err := container.Exec(ctx, []string{"cat", "/tmp/pre-start"}, tcexec.Multiplexed())
+	require.NoError(t, err)
+	require.Equal(t, 0, exitCode)
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "wrapped", strings.TrimSpace(string(output)))
+}
+
 func TestPutWithEnterprise(t *testing.T) {
 	skipIfDockerNotAvailable(t)
 
@@ -395,7 +960,12 @@ func FuzzWithNamespace(f *testing.F) {
 		opt := WithNamespace(namespace)
 
 		err := opt.Customize(req)
+		trimmed := strings.TrimSpace(namespace)
+		if trimmed == "" {
+			require.ErrorIs(t, err, ErrInvalidOption)
+			return
+		}
 		require.NoError(t, err)
-		assert.Equal(t, namespace, req.Env["NAMESPACE"])
+		assert.Equal(t, trimmed, req.Env["NAMESPACE"])
 	})
 }
@@ -0,0 +1,138 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrToolNotAvailable is returned when an Aerospike tool is not installed
+	// in the container image.
+	ErrToolNotAvailable = errors.New("tool not available in container")
+	// ErrToolFailed is returned when an Aerospike tool exits with a non-zero
+	// status.
+	ErrToolFailed = errors.New("tool failed")
+	// ErrUnexpectedToolOutput is returned when the output of an Aerospike tool
+	// cannot be parsed.
+	ErrUnexpectedToolOutput = errors.New("unexpected tool output")
+)
+
+// asbenchSegmentPattern matches the per-operation segments of an asbench
+// progress line, such as "read(tps=5678 (hit=5678 miss=0) timeouts=0 errors=0)".
+var asbenchSegmentPattern = regexp.MustCompile(`\b(read|write|total)\(((?:[^()]|\([^()]*\))*)\)`)
+
+// asbenchCounterPattern matches the counters within an asbench segment.
+var asbenchCounterPattern = regexp.MustCompile(`\b(tps|timeouts|errors)=(\d+)`)
+
+// AsbenchStats summarizes one kind of operation over an asbench run.
+type AsbenchStats struct {
+	// TPS is the mean number of transactions per second over the run.
+	TPS float64
+	// Timeouts and Errors are the totals over the run.
+	Timeouts int64
+	Errors   int64
+}
+
+// AsbenchResult is the parsed outcome of an asbench run.
+type AsbenchResult struct {
+	Read  AsbenchStats
+	Write AsbenchStats
+	Total AsbenchStats
+	// Intervals is the number of progress lines the summary is computed from,
+	// normally one per second of the run.
+	Intervals int
+	// Output is the raw asbench output, for latency histograms and anything
+	// else the summary does not cover.
+	Output string
+}
+
+// Asbench runs the asbench benchmark tool inside the container against the
+// local server and summarizes its throughput. args are passed to asbench after
+// the host, the port and the credentials set with WithSecurity, so they can
+// select the namespace, workload and duration, for example
+// []string{"-n", "test", "-w", "RU,80", "-d", "10"}. The run is bounded by ctx
+// only, so without a duration in args asbench runs until ctx is done.
+//
+// ErrToolNotAvailable is returned when the image does not ship asbench.
+func (c Container) Asbench(ctx context.Context, args []string) (AsbenchResult, error) {
+	if err := c.requireTool(ctx, "asbench"); err != nil {
+		return AsbenchResult{}, err
+	}
+
+	cmd := []string{"asbench", "-h", "127.0.0.1", "-p", strconv.Itoa(c.settings.serviceContainerPort())}
+	if c.settings.user != "" {
+		// As with the other tools, the password must be attached to the flag.
+		cmd = append(cmd, "--user", c.settings.user, "--password="+c.settings.password)
+	}
+	cmd = append(cmd, args...)
+	result, err := runExec(ctx, c.Container, cmd)
+	if err != nil {
+		return AsbenchResult{}, err
+	}
+	// asbench logs its progress lines to stdout or stderr depending on the
+	// version, so both are parsed.
+	output := result.stdout + result.stderr
+	if result.exitCode != 0 {
+		return AsbenchResult{}, fmt.Errorf("%w: asbench exited with code %d: %s", ErrToolFailed, result.exitCode, strings.TrimSpace(output))
+	}
+
+	return parseAsbenchOutput(output)
+}
+
+// parseAsbenchOutput averages the throughput and sums the timeouts and errors
+// reported on asbench's per-interval progress lines.
+func parseAsbenchOutput(output string) (AsbenchResult, error) {
+	result := AsbenchResult{Output: output}
+	var tps [3]int64
+
+	for _, line := range strings.Split(output, "\n") {
+		segments := asbenchSegmentPattern.FindAllStringSubmatch(line, -1)
+		if len(segments) == 0 {
+			continue
+		}
+		result.Intervals++
+
+		for _, segment := range segments {
+			var stats *AsbenchStats
+			var sum *int64
+			switch segment[1] {
+			case "read":
+				stats, sum = &result.Read, &tps[0]
+			case "write":
+				stats, sum = &result.Write, &tps[1]
+			default:
+				stats, sum = &result.Total, &tps[2]
+			}
+
+			for _, counter := range asbenchCounterPattern.FindAllStringSubmatch(segment[2], -1) {
+				n, err := strconv.ParseInt(counter[2], 10, 64)
+				if err != nil {
+					return result, fmt.Errorf("%w: asbench %s %s=%q", ErrUnexpectedToolOutput, segment[1], counter[1], counter[2])
+				}
+				switch counter[1] {
+				case "tps":
+					*sum += n
+				case "timeouts":
+					stats.Timeouts += n
+				default:
+					stats.Errors += n
+				}
+			}
+		}
+	}
+
+	if result.Intervals == 0 {
+		return result, fmt.Errorf("%w: asbench reported no throughput", ErrUnexpectedToolOutput)
+	}
+
+	intervals := float64(result.Intervals)
+	result.Read.TPS = float64(tps[0]) / intervals
+	result.Write.TPS = float64(tps[1]) / intervals
+	result.Total.TPS = float64(tps[2]) / intervals
+
+	return result, nil
+}
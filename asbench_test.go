@@ -0,0 +1,66 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const asbenchOutputSample = `2024-05-02 10:00:00.000 INFO Start 2 transaction threads
+2024-05-02 10:00:01.000 INFO write(tps=1000 timeouts=0 errors=0) read(tps=4000 (hit=4000 miss=0) timeouts=1 errors=0) total(tps=5000 timeouts=1 errors=0)
+2024-05-02 10:00:02.000 INFO write(tps=3000 timeouts=0 errors=2) read(tps=6000 (hit=6000 miss=0) timeouts=0 errors=0) total(tps=9000 timeouts=0 errors=2)
+`
+
+func TestParseAsbenchOutput(t *testing.T) {
+	result, err := parseAsbenchOutput(asbenchOutputSample)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Intervals)
+	assert.Equal(t, AsbenchStats{TPS: 5000, Timeouts: 1}, result.Read)
+	assert.Equal(t, AsbenchStats{TPS: 2000, Errors: 2}, result.Write)
+	assert.Equal(t, AsbenchStats{TPS: 7000, Timeouts: 1, Errors: 2}, result.Total)
+	assert.Equal(t, asbenchOutputSample, result.Output)
+}
+
+func TestParseAsbenchOutputWithoutProgress(t *testing.T) {
+	_, err := parseAsbenchOutput("ERROR Failed to connect\n")
+	require.ErrorIs(t, err, ErrUnexpectedToolOutput)
+}
+
+func TestAsbenchNotAvailable(t *testing.T) {
+	var commands [][]string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			commands = append(commands, cmd)
+			return 1, "", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	_, err := c.Asbench(context.Background(), []string{"-d", "1"})
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Len(t, commands, 1)
+}
+
+func TestAsbench(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	result, err := container.Asbench(ctx, []string{"-n", "test", "-k", "1000", "-w", "RU,50", "-d", "2"})
+	if errors.Is(err, ErrToolNotAvailable) {
+		t.Skip("asbench is not installed in the server image")
+	}
+	require.NoError(t, err)
+
+	assert.Positive(t, result.Intervals)
+	assert.Positive(t, result.Total.TPS)
+}
@@ -0,0 +1,170 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// asrestoreCounterPattern matches the record counters of asrestore, in both
+// the "inserted 100" and the "Inserted records: 100" forms.
+var asrestoreCounterPattern = regexp.MustCompile(`(?i)\b(inserted|existed|fresher|expired|failed)(?: records)?\s*[:=]?\s*(\d+)`)
+
+// Backup writes a backup of namespace to w, taken with asbackup inside the
+// container. The backup is written to a temporary file in the container, which
+// is removed once it has been streamed to w. Pass it to Restore to bring the
+// namespace back to the same state later in the test.
+//
+// ErrToolNotAvailable is returned when the image does not ship asbackup, as
+// some community images do not.
+func (c Container) Backup(ctx context.Context, namespace string, w io.Writer) error {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+	if err := c.requireTool(ctx, "asbackup"); err != nil {
+		return err
+	}
+
+	path := backupPath(namespace)
+	defer c.removeFile(path)
+
+	if _, err := c.runTool(ctx, "asbackup", "--namespace", namespace, "--output-file", path); err != nil {
+		return err
+	}
+
+	backup, err := c.CopyFileFromContainer(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup of namespace %q: %w", namespace, err)
+	}
+	defer backup.Close()
+
+	if _, err := io.Copy(w, backup); err != nil {
+		return fmt.Errorf("failed to write backup of namespace %q: %w", namespace, err)
+	}
+
+	return nil
+}
+
+// RestoreResult is the outcome of a Restore, as reported by asrestore.
+type RestoreResult struct {
+	// Inserted is the number of records written.
+	Inserted int64
+	// Existed and Fresher count records left alone because they already
+	// existed or the stored copy was newer, which only happens when asrestore
+	// is told not to replace records.
+	Existed int64
+	Fresher int64
+	// Expired counts records whose TTL had elapsed by the time of the restore.
+	Expired int64
+	// Failed counts records the server rejected.
+	Failed int64
+	// Output is the raw asrestore output.
+	Output string
+}
+
+// Restore restores a backup written by Backup with asrestore inside the
+// container and waits for it to finish. Records in the backup replace the
+// stored records with the same key; records written since the backup was taken
+// are kept. The returned result holds the record counts asrestore reports.
+//
+// The official aerospike/aerospike-server and
+// aerospike/aerospike-server-enterprise images bundle asbackup and asrestore
+// with the Aerospike tools; ErrToolNotAvailable is returned for images that do
+// not.
+func (c Container) Restore(ctx context.Context, r io.Reader) (RestoreResult, error) {
+	if err := c.requireTool(ctx, "asrestore"); err != nil {
+		return RestoreResult{}, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	path := backupPath("restore")
+	if err := c.CopyToContainer(ctx, data, path, 0o600); err != nil {
+		return RestoreResult{}, fmt.Errorf("failed to copy backup into container: %w", err)
+	}
+	defer c.removeFile(path)
+
+	output, err := c.runTool(ctx, "asrestore", "--input-file", path)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	return parseAsrestoreOutput(output)
+}
+
+// runTool runs an Aerospike tool such as asbackup against the local server,
+// authenticating with the credentials set with WithSecurity, and returns its
+// combined output.
+func (c Container) runTool(ctx context.Context, tool string, args ...string) (string, error) {
+	cmd := []string{tool, "--host", "127.0.0.1", "--port", strconv.Itoa(c.settings.serviceContainerPort())}
+	if c.settings.user != "" {
+		// The tools take the password as an optional argument, which must be
+		// attached to the flag.
+		cmd = append(cmd, "--user", c.settings.user, "--password="+c.settings.password)
+	}
+	cmd = append(cmd, args...)
+
+	result, err := runExec(ctx, c.Container, cmd)
+	if err != nil {
+		return "", err
+	}
+	// The tools log to stderr, so both streams are kept.
+	output := result.stdout + result.stderr
+	if result.exitCode != 0 {
+		return "", fmt.Errorf("%w: %s exited with code %d: %s", ErrToolFailed, tool, result.exitCode, strings.TrimSpace(output))
+	}
+
+	return output, nil
+}
+
+// parseAsrestoreOutput extracts the record counts from asrestore's summary,
+// such as "inserted 100: failed 0 (existed 0, fresher 0)". asrestore repeats
+// the counts on its progress lines, so the last value of each wins.
+func parseAsrestoreOutput(output string) (RestoreResult, error) {
+	result := RestoreResult{Output: output}
+	counters := map[string]*int64{
+		"inserted": &result.Inserted,
+		"existed":  &result.Existed,
+		"fresher":  &result.Fresher,
+		"expired":  &result.Expired,
+		"failed":   &result.Failed,
+	}
+
+	found := false
+	for _, match := range asrestoreCounterPattern.FindAllStringSubmatch(output, -1) {
+		n, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			return result, fmt.Errorf("%w: asrestore %s=%q", ErrUnexpectedToolOutput, match[1], match[2])
+		}
+		name := strings.ToLower(match[1])
+		*counters[name] = n
+		found = found || name == "inserted"
+	}
+	if !found {
+		return result, fmt.Errorf("%w: asrestore reported no inserted records count", ErrUnexpectedToolOutput)
+	}
+
+	return result, nil
+}
+
+// removeFile removes path from the container on a best-effort basis, using a
+// fresh context so cleanup still runs after ctx is done.
+func (c Container) removeFile(path string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.settings.infoTimeout)
+	defer cancel()
+
+	_, _ = runExec(ctx, c.Container, []string{"rm", "-f", path})
+}
+
+// backupPath returns a unique path in the container for a backup file.
+func backupPath(name string) string {
+	return "/tmp/testcontainers-" + name + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + ".asb"
+}
@@ -0,0 +1,139 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolContainer returns a Container whose commands all succeed. asbackup
+// writes backup to its output file and asrestore records the content of its
+// input file in restored and reports one inserted record.
+func toolContainer(backup string, restored *string, commands *[][]string) Container {
+	fake := &fakeContainer{files: map[string][]byte{}}
+	fake.exec = func(_ context.Context, cmd []string) (int, string, error) {
+		*commands = append(*commands, cmd)
+		switch cmd[0] {
+		case "asbackup":
+			fake.files[cmd[len(cmd)-1]] = []byte(backup)
+		case "asrestore":
+			*restored = string(fake.files[cmd[len(cmd)-1]])
+			return 0, "Expired 0 : skipped 0 : err_ignored 0 : inserted 1: failed 0 (existed 0, fresher 0)\n", nil
+		}
+		return 0, "", nil
+	}
+
+	return Container{Container: fake, settings: defaultOptions()}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	var restored string
+	var commands [][]string
+	c := toolContainer("Version 3.1\n# namespace test\n", &restored, &commands)
+
+	var backup bytes.Buffer
+	require.NoError(t, c.Backup(context.Background(), "test", &backup))
+	assert.Equal(t, "Version 3.1\n# namespace test\n", backup.String())
+
+	result, err := c.Restore(context.Background(), &backup)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Inserted)
+	assert.Equal(t, "Version 3.1\n# namespace test\n", restored)
+
+	require.Len(t, commands, 6)
+	assert.Equal(t, []string{"sh", "-c", "command -v asbackup"}, commands[0])
+	backupFile := commands[1][len(commands[1])-1]
+	assert.Equal(t, []string{"asbackup", "--host", "127.0.0.1", "--port", "3000", "--namespace", "test", "--output-file", backupFile}, commands[1])
+	assert.Equal(t, []string{"rm", "-f", backupFile}, commands[2])
+	assert.Equal(t, []string{"sh", "-c", "command -v asrestore"}, commands[3])
+	restoreFile := commands[4][len(commands[4])-1]
+	assert.Equal(t, []string{"asrestore", "--host", "127.0.0.1", "--port", "3000", "--input-file", restoreFile}, commands[4])
+	assert.Equal(t, []string{"rm", "-f", restoreFile}, commands[5])
+}
+
+func TestParseAsrestoreOutput(t *testing.T) {
+	output := `2024-05-02 10:00:01 INFO [asrestore] Expired 0 : skipped 0 : err_ignored 0 : inserted 40: failed 0 (existed 0, fresher 0)
+2024-05-02 10:00:02 INFO [asrestore] Expired 2 : skipped 0 : err_ignored 0 : inserted 97: failed 1 (existed 3, fresher 4)
+`
+	result, err := parseAsrestoreOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, RestoreResult{Inserted: 97, Existed: 3, Fresher: 4, Expired: 2, Failed: 1, Output: output}, result)
+
+	result, err = parseAsrestoreOutput("Inserted records: 12\nFailed records: 0\n")
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), result.Inserted)
+
+	_, err = parseAsrestoreOutput("ERROR Failed to connect\n")
+	require.ErrorIs(t, err, ErrUnexpectedToolOutput)
+}
+
+func TestBackupToolNotAvailable(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			return 1, "", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	err := c.Backup(context.Background(), "test", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Contains(t, err.Error(), "asbackup")
+
+	_, err = c.Restore(context.Background(), strings.NewReader("backup"))
+	require.ErrorIs(t, err, ErrToolNotAvailable)
+	assert.Contains(t, err.Error(), "asrestore")
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	records := make([]SeedRecord, 100)
+	for i := range records {
+		records[i] = SeedRecord{Key: i, Bins: aerospike.BinMap{"n": i}}
+	}
+	require.NoError(t, container.Seed(ctx, "test", "backup", records))
+
+	before, err := container.ChecksumSet(ctx, "test", "backup")
+	require.NoError(t, err)
+
+	var backup bytes.Buffer
+	err = container.Backup(ctx, "test", &backup)
+	if errors.Is(err, ErrToolNotAvailable) {
+		t.Skip("asbackup is not installed in the server image")
+	}
+	require.NoError(t, err)
+	require.NotZero(t, backup.Len())
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	require.NoError(t, client.Truncate(nil, "test", "backup", nil))
+
+	require.Eventually(t, func() bool {
+		count, err := container.QueryCount(ctx, "test", "backup", nil)
+		return err == nil && count == 0
+	}, 10*time.Second, 100*time.Millisecond)
+
+	result, err := container.Restore(ctx, &backup)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), result.Inserted)
+	assert.Zero(t, result.Failed)
+
+	after, err := container.ChecksumSet(ctx, "test", "backup")
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
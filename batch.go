@@ -0,0 +1,89 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+)
+
+// ErrUnsupportedServerVersion is returned when a helper needs a newer server
+// than the one running in the container.
+var ErrUnsupportedServerVersion = errors.New("unsupported server version")
+
+// BatchOperate runs a batch that may mix reads, writes, deletes and UDF calls
+// (*aerospike.BatchRead, *aerospike.BatchWrite, *aerospike.BatchDelete and
+// *aerospike.BatchUDF). As with the client, the outcome of each operation is
+// stored on its record, so partial failures can be inspected through each
+// record's BatchRec().ResultCode and Err fields. Reads in the batch use the
+// level set with WithReadConsistency.
+//
+// Heterogeneous batches need server 6.0 or later, which is checked before the
+// batch is sent.
+func (c Container) BatchOperate(ctx context.Context, records []aerospike.BatchRecordIfc) error {
+	version, err := c.serverVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version[0] < 6 {
+		return fmt.Errorf("%w: batch operate needs server 6.0 or later", ErrUnsupportedServerVersion)
+	}
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy := aerospike.NewBatchPolicy()
+	if err := c.applyReadPolicy(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+
+	if aerr := client.BatchOperate(policy, records); aerr != nil {
+		return fmt.Errorf("batch operate failed: %w", aerr)
+	}
+
+	return nil
+}
+
+// BatchExists reports which of keys exist in namespace.set, in the same order
+// as keys. It reads record headers only, which is cheaper than fetching the
+// records with a batch get.
+func (c Container) BatchExists(ctx context.Context, namespace, set string, keys []any) ([]bool, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return nil, err
+	}
+	set = strings.TrimSpace(set)
+
+	batchKeys := make([]*aerospike.Key, len(keys))
+	for i, k := range keys {
+		key, aerr := aerospike.NewKey(namespace, set, k)
+		if aerr != nil {
+			return nil, fmt.Errorf("invalid key %v at index %d: %w", k, i, aerr)
+		}
+		batchKeys[i] = key
+	}
+	if len(batchKeys) == 0 {
+		return []bool{}, nil
+	}
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := aerospike.NewBatchPolicy()
+	if err := c.applyReadPolicy(ctx, &policy.BasePolicy); err != nil {
+		return nil, err
+	}
+
+	exists, aerr := client.BatchExists(policy, batchKeys)
+	if aerr != nil {
+		return nil, fmt.Errorf("batch exists failed: %w", aerr)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,99 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchOperateRejectsOldServer(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			return 0, "5.7.0.8", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	err := c.BatchOperate(context.Background(), nil)
+	require.ErrorIs(t, err, ErrUnsupportedServerVersion)
+}
+
+func TestBatchOperate(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	existing, err := aerospike.NewKey("test", "batch", "existing")
+	require.NoError(t, err)
+	doomed, err := aerospike.NewKey("test", "batch", "doomed")
+	require.NoError(t, err)
+	created, err := aerospike.NewKey("test", "batch", "created")
+	require.NoError(t, err)
+	missing, err := aerospike.NewKey("test", "batch", "missing")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Put(nil, existing, aerospike.BinMap{"bin": "value"}))
+	require.NoError(t, client.Put(nil, doomed, aerospike.BinMap{"bin": "value"}))
+
+	read := aerospike.NewBatchRead(nil, existing, nil)
+	write := aerospike.NewBatchWrite(nil, created, aerospike.PutOp(aerospike.NewBin("bin", "new")))
+	del := aerospike.NewBatchDelete(nil, doomed)
+	readMissing := aerospike.NewBatchRead(nil, missing, nil)
+
+	err = container.BatchOperate(ctx, []aerospike.BatchRecordIfc{read, write, del, readMissing})
+	require.NoError(t, err)
+
+	assert.Equal(t, types.OK, read.ResultCode)
+	assert.Equal(t, "value", read.Record.Bins["bin"])
+	assert.Equal(t, types.OK, write.ResultCode)
+	assert.Equal(t, types.OK, del.ResultCode)
+	assert.Equal(t, types.KEY_NOT_FOUND_ERROR, readMissing.ResultCode)
+
+	exists, err := client.Exists(nil, doomed)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestBatchExists(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	for _, id := range []any{"a", 2} {
+		key, err := aerospike.NewKey("test", "exists", id)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+	}
+
+	exists, err := container.BatchExists(ctx, "test", "exists", []any{"a", "missing", 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true, false}, exists)
+}
@@ -0,0 +1,163 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const defaultClientTimeout = 5 * time.Second
+
+// ErrContainerNotRunning is returned when a client or a mapped port is
+// requested for a container that is not running.
+var ErrContainerNotRunning = errors.New("container is not running")
+
+// NewClient returns a client connected to the container's mapped service port,
+// with a 5s connection timeout, the tend interval set with
+// WithClientTendInterval and the credentials set with WithSecurity. When TLS
+// is enabled it connects to the TLS port instead, trusting the certificate
+// authority returned by TLSCACert. It works the same for community and
+// enterprise images. The caller owns the client and must Close it.
+func (c Container) NewClient(ctx context.Context) (*aerospike.Client, error) {
+	if err := c.requireRunning(ctx); err != nil {
+		return nil, err
+	}
+
+	clientPolicy := aerospike.NewClientPolicy()
+	clientPolicy.Timeout = defaultClientTimeout
+	if c.settings.tendInterval > 0 {
+		clientPolicy.TendInterval = c.settings.tendInterval
+	}
+	clientPolicy.User = c.settings.user
+	clientPolicy.Password = c.settings.password
+
+	var seed *aerospike.Host
+	if c.settings.tls != nil {
+		var err error
+		if seed, err = c.tlsHost(ctx, clientPolicy); err != nil {
+			return nil, err
+		}
+	} else {
+		host, port, err := c.HostPort(ctx)
+		if err != nil {
+			return nil, err
+		}
+		seed = aerospike.NewHost(host, port)
+	}
+
+	client, aerr := aerospike.NewClientWithPolicyAndHost(clientPolicy, seed)
+	if aerr != nil {
+		return nil, fmt.Errorf("failed to connect to Aerospike: %w", aerr)
+	}
+
+	return client, nil
+}
+
+// Client returns a client connected to the container, created with NewClient
+// on first use and shared by every later call and by the Container helpers,
+// so tests that run many helpers do not pay for a connection each time. The
+// client belongs to the Container: do not Close it, Terminate does. When the
+// container has been restarted since the client was created, the stale client
+// is closed and a new one is connected, as the mapped ports may have changed.
+func (c Container) Client(ctx context.Context) (*aerospike.Client, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("%w: container was not created by RunContainer", ErrContainerNotRunning)
+	}
+
+	state, err := c.State(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if !state.Running {
+		return nil, fmt.Errorf("%w: container is %s", ErrContainerNotRunning, state.Status)
+	}
+
+	c.client.mu.Lock()
+	defer c.client.mu.Unlock()
+
+	if c.client.client != nil && c.client.startedAt == state.StartedAt {
+		return c.client.client, nil
+	}
+	c.client.closeLocked()
+
+	client, err := c.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.client.client = client
+	c.client.startedAt = state.StartedAt
+
+	return client, nil
+}
+
+// Terminate closes the client returned by Client and terminates the
+// container.
+func (c Container) Terminate(ctx context.Context, opts ...testcontainers.TerminateOption) error {
+	if c.client != nil {
+		c.client.close()
+	}
+
+	return c.Container.Terminate(ctx, opts...)
+}
+
+// applyReadPolicy prepares policy for a read issued by one of the Container
+// helpers: it applies the deadline of ctx and the level set with
+// WithReadConsistency. It returns the error of ctx when ctx is already done.
+func (c Container) applyReadPolicy(ctx context.Context, policy *aerospike.BasePolicy) error {
+	if err := applyDeadline(ctx, policy); err != nil {
+		return err
+	}
+	if c.settings.readLevel == ConsistencyAll {
+		policy.ReadModeAP = aerospike.ReadModeAPAll
+	} else {
+		policy.ReadModeAP = aerospike.ReadModeAPOne
+	}
+
+	return nil
+}
+
+// applyDeadline bounds a synchronous client call by the deadline of ctx, as
+// the client does not take a context itself. It returns the error of ctx when
+// ctx is already done, as the client would take a timeout that is not positive
+// to mean no timeout at all.
+func applyDeadline(ctx context.Context, policy *aerospike.BasePolicy) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		policy.TotalTimeout = time.Until(deadline)
+	}
+
+	return nil
+}
+
+// sharedClient holds the client returned by Container.Client. Container is
+// passed by value, so it keeps a pointer to one sharedClient. A mutex rather
+// than a sync.Once guards it, as the client is replaced after a restart.
+type sharedClient struct {
+	mu     sync.Mutex
+	client *aerospike.Client
+	// startedAt is the start time of the container the client connected to.
+	startedAt string
+}
+
+// close closes the client, if any, so the next Container.Client call
+// connects again.
+func (s *sharedClient) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeLocked()
+}
+
+func (s *sharedClient) closeLocked() {
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+}
@@ -0,0 +1,148 @@
+package aerospike
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestNewClient(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	tests := []struct {
+		name string
+		opts []testcontainers.ContainerCustomizer
+	}{
+		{name: "community"},
+		{name: "enterprise", opts: []testcontainers.ContainerCustomizer{WithEnterpriseEdition()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			container := startContainer(ctx, t, tt.opts...)
+			t.Cleanup(func() {
+				require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+			})
+
+			client, err := container.NewClient(ctx)
+			require.NoError(t, err)
+			t.Cleanup(client.Close)
+			assert.True(t, client.IsConnected())
+
+			key, err := aerospike.NewKey("test", "client", "key")
+			require.NoError(t, err)
+			require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+		})
+	}
+}
+
+func TestNewClientRequiresRunningContainer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	require.NoError(t, container.Stop(ctx, nil))
+
+	_, err := container.NewClient(ctx)
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+}
+
+func TestClientRequiresRunningContainer(t *testing.T) {
+	_, err := Container{Container: &fakeContainer{}}.Client(context.Background())
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+
+	c := Container{
+		Container: &fakeContainer{state: &container.State{Status: container.StateExited}},
+		client:    &sharedClient{},
+	}
+	_, err = c.Client(context.Background())
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+}
+
+func TestApplyDeadline(t *testing.T) {
+	defaultTimeout := aerospike.NewPolicy().TotalTimeout
+
+	policy := aerospike.NewPolicy()
+	require.NoError(t, applyDeadline(context.Background(), policy))
+	assert.Equal(t, defaultTimeout, policy.TotalTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, applyDeadline(ctx, policy))
+	assert.Greater(t, policy.TotalTimeout, defaultTimeout)
+
+	// An expired context fails up front rather than leaving a timeout that is
+	// not positive, which the client would not enforce.
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	policy = aerospike.NewPolicy()
+	require.ErrorIs(t, applyDeadline(expired, policy), context.DeadlineExceeded)
+	assert.Equal(t, defaultTimeout, policy.TotalTimeout)
+}
+
+func TestClientIsShared(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	c := startContainer(ctx, t)
+
+	client, err := c.Client(ctx)
+	require.NoError(t, err)
+	again, err := c.Client(ctx)
+	require.NoError(t, err)
+	assert.Same(t, client, again)
+
+	// A restart maps new ports, so the stale client is replaced.
+	require.NoError(t, c.Stop(ctx, nil))
+	require.NoError(t, c.Start(ctx))
+	restarted, err := c.Client(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, client, restarted)
+	assert.False(t, client.IsConnected())
+	assert.True(t, restarted.IsConnected())
+
+	require.NoError(t, c.Terminate(ctx))
+	assert.False(t, restarted.IsConnected())
+}
+
+func TestConnectionString(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, port, err := container.HostPort(ctx)
+	require.NoError(t, err)
+
+	endpoint, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, net.JoinHostPort(host, strconv.Itoa(port)), endpoint)
+
+	// The endpoint is enough to build a client elsewhere.
+	hosts, aerr := aerospike.NewHosts(endpoint)
+	require.NoError(t, aerr)
+	client, aerr := aerospike.NewClientWithPolicyAndHost(nil, hosts...)
+	require.NoError(t, aerr)
+	t.Cleanup(client.Close)
+	assert.True(t, client.IsConnected())
+}
@@ -0,0 +1,264 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/network"
+)
+
+// clusterFormTimeout bounds how long RunCluster waits for every node to see
+// the whole cluster.
+const clusterFormTimeout = 2 * time.Minute
+
+// Cluster is a group of Aerospike containers that form a single cluster over
+// a shared Docker network.
+type Cluster struct {
+	nodes   []*Container
+	network *testcontainers.DockerNetwork
+}
+
+// RunCluster starts nodes Aerospike containers on a new Docker network, each
+// configured with opts, and waits until they have formed a single cluster with
+// no migrations pending. Nodes find each other through mesh heartbeats: every
+// node is seeded with the network aliases of the nodes started before it.
+//
+// opts apply to every node, except those wrapped in WithClusterNode, which
+// only apply to one. They must not give the nodes conflicting settings such as
+// a container name. On failure, any node already started is terminated and the
+// network removed.
+func RunCluster(ctx context.Context, nodes int, opts ...testcontainers.ContainerCustomizer) (_ *Cluster, err error) {
+	if nodes < 1 {
+		return nil, fmt.Errorf("%w: a cluster needs at least one node, got %d", ErrInvalidArgument, nodes)
+	}
+
+	shared, perNode, err := splitClusterOptions(nodes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster network: %w", err)
+	}
+
+	cluster := &Cluster{network: nw}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, cluster.Terminate(ctx))
+		}
+	}()
+
+	aliases := make([]string, 0, nodes)
+	for i := range nodes {
+		alias := clusterNodeAlias(i)
+		nodeOpts := append(append([]testcontainers.ContainerCustomizer{}, shared...), perNode[i]...)
+		nodeOpts = append(nodeOpts,
+			network.WithNetwork([]string{alias}, nw),
+			withMeshSeeds(aliases),
+		)
+
+		node, err := RunContainer(ctx, nodeOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start cluster node %d: %w", i+1, err)
+		}
+		cluster.nodes = append(cluster.nodes, node)
+		aliases = append(aliases, alias)
+	}
+
+	for i, node := range cluster.nodes {
+		if err := node.WaitForStableCluster(ctx, nodes, clusterFormTimeout); err != nil {
+			return nil, fmt.Errorf("cluster node %d did not join the cluster: %w", i+1, err)
+		}
+	}
+
+	return cluster, nil
+}
+
+// WithClusterNode applies opts to the node at index, counting from zero in the
+// order of Cluster.Nodes, instead of to every node of a cluster started with
+// RunCluster. Use it for settings that differ between nodes, such as
+// WithRackID. It is rejected by RunContainer.
+func WithClusterNode(index int, opts ...testcontainers.ContainerCustomizer) testcontainers.ContainerCustomizer {
+	return clusterNodeOption{index: index, opts: opts}
+}
+
+// clusterNodeOption holds the options set with WithClusterNode.
+type clusterNodeOption struct {
+	index int
+	opts  []testcontainers.ContainerCustomizer
+}
+
+// Customize rejects the option, as it is only meaningful to RunCluster.
+func (o clusterNodeOption) Customize(*testcontainers.GenericContainerRequest) error {
+	return fmt.Errorf("%w: WithClusterNode only applies to RunCluster", ErrInvalidOption)
+}
+
+// Nodes returns the containers of the cluster in the order they were started.
+func (c *Cluster) Nodes() []*Container {
+	return c.nodes
+}
+
+// Seeds returns the mapped service endpoint of every node, reachable from the
+// test process. The nodes advertise their addresses on the cluster network,
+// which the test process cannot reach, so clients built from these seeds must
+// set ClientPolicy.SeedOnlyCluster; NewClient does so.
+func (c *Cluster) Seeds(ctx context.Context) ([]*aerospike.Host, error) {
+	seeds := make([]*aerospike.Host, 0, len(c.nodes))
+	for _, node := range c.nodes {
+		host, port, err := node.HostPort(ctx)
+		if err != nil {
+			return nil, err
+		}
+		seeds = append(seeds, aerospike.NewHost(host, port))
+	}
+
+	return seeds, nil
+}
+
+// NewClient returns a client connected to every node of the cluster through
+// the endpoints returned by Seeds, with the same policy as
+// Container.NewClient. The caller owns the client and must Close it.
+func (c *Cluster) NewClient(ctx context.Context) (*aerospike.Client, error) {
+	if len(c.nodes) == 0 {
+		return nil, fmt.Errorf("%w: cluster has no nodes", ErrContainerNotRunning)
+	}
+
+	seeds, err := c.Seeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := c.nodes[0].settings
+	clientPolicy := aerospike.NewClientPolicy()
+	clientPolicy.Timeout = defaultClientTimeout
+	if settings.tendInterval > 0 {
+		clientPolicy.TendInterval = settings.tendInterval
+	}
+	clientPolicy.User = settings.user
+	clientPolicy.Password = settings.password
+	clientPolicy.SeedOnlyCluster = true
+
+	client, aerr := aerospike.NewClientWithPolicyAndHost(clientPolicy, seeds...)
+	if aerr != nil {
+		return nil, fmt.Errorf("failed to connect to Aerospike cluster: %w", aerr)
+	}
+
+	return client, nil
+}
+
+// TerminateNode removes the node at index from the cluster and terminates it.
+// On the enterprise edition the node is quiesced first and every node is asked
+// to recluster, so the principal acts on it, and TerminateNode waits until
+// the other nodes have taken over its partitions; writes in flight and
+// records held only by the node are then not lost, and the remaining nodes do
+// not log errors about a vanished peer. The community edition cannot quiesce,
+// so there the node is terminated straight away. The node is dropped from
+// Nodes, so the nodes after it move down one index.
+func (c *Cluster) TerminateNode(ctx context.Context, index int) error {
+	if index < 0 || index >= len(c.nodes) {
+		return fmt.Errorf("%w: node index %d is outside 0-%d", ErrInvalidArgument, index, len(c.nodes)-1)
+	}
+	node := c.nodes[index]
+
+	version, err := node.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version.IsEnterprise() && len(c.nodes) > 1 {
+		if err := node.Quiesce(ctx); err != nil {
+			return fmt.Errorf("failed to quiesce cluster node %d: %w", index+1, err)
+		}
+		for i, peer := range c.nodes {
+			if err := peer.recluster(ctx); err != nil {
+				return fmt.Errorf("failed to recluster through node %d: %w", i+1, err)
+			}
+		}
+		if err := node.waitForQuiesced(ctx); err != nil {
+			return fmt.Errorf("cluster node %d: %w", index+1, err)
+		}
+		for i, peer := range c.nodes {
+			if err := peer.WaitForMigrations(ctx, clusterFormTimeout); err != nil {
+				return fmt.Errorf("cluster node %d did not finish migrating: %w", i+1, err)
+			}
+		}
+	}
+
+	if err := node.Terminate(ctx); err != nil {
+		return fmt.Errorf("failed to terminate cluster node %d: %w", index+1, err)
+	}
+	c.nodes = slices.Delete(c.nodes, index, index+1)
+
+	return nil
+}
+
+// Terminate terminates every node and removes the cluster network.
+func (c *Cluster) Terminate(ctx context.Context) error {
+	var errs []error
+	for i, node := range c.nodes {
+		if err := node.Terminate(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to terminate cluster node %d: %w", i+1, err))
+		}
+	}
+	c.nodes = nil
+
+	if c.network != nil {
+		if err := c.network.Remove(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove cluster network: %w", err))
+		}
+		c.network = nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// splitClusterOptions separates the options of RunCluster into those shared by
+// every node and those set with WithClusterNode for each of nodes.
+func splitClusterOptions(nodes int, opts []testcontainers.ContainerCustomizer) ([]testcontainers.ContainerCustomizer, [][]testcontainers.ContainerCustomizer, error) {
+	shared := make([]testcontainers.ContainerCustomizer, 0, len(opts))
+	perNode := make([][]testcontainers.ContainerCustomizer, nodes)
+	for _, opt := range opts {
+		nodeOpt, ok := opt.(clusterNodeOption)
+		if !ok {
+			shared = append(shared, opt)
+			continue
+		}
+		if nodeOpt.index < 0 || nodeOpt.index >= nodes {
+			return nil, nil, fmt.Errorf("%w: cluster node index %d is out of range for %d nodes", ErrInvalidOption, nodeOpt.index, nodes)
+		}
+		perNode[nodeOpt.index] = append(perNode[nodeOpt.index], nodeOpt.opts...)
+	}
+
+	return shared, perNode, nil
+}
+
+// clusterNodeAlias returns the network alias of the i-th cluster node,
+// counting from zero.
+func clusterNodeAlias(i int) string {
+	return "aerospike-node-" + strconv.Itoa(i+1)
+}
+
+// withMeshSeeds makes the heartbeat listen on every interface and seeds the
+// mesh with the heartbeat port of each of peers.
+func withMeshSeeds(peers []string) Option {
+	peers = append([]string(nil), peers...)
+
+	return func(o *options) error {
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			heartbeat := cfg.network().child("heartbeat")
+			heartbeat.set("address", "any")
+			for _, peer := range peers {
+				heartbeat.add("mesh-seed-address-port", peer+" 3002")
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
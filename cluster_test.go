@@ -0,0 +1,186 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestWithMeshSeedsRendersHeartbeat(t *testing.T) {
+	config := renderedConfig(t, withMeshSeeds([]string{clusterNodeAlias(0), clusterNodeAlias(1)}))
+
+	assert.Contains(t, config, "\t\taddress any\n\t\tport 3002\n")
+	assert.Contains(t, config, "\t\tmesh-seed-address-port aerospike-node-1 3002\n\t\tmesh-seed-address-port aerospike-node-2 3002\n")
+}
+
+func TestWithMeshSeedsWithoutPeers(t *testing.T) {
+	config := renderedConfig(t, withMeshSeeds(nil))
+
+	assert.NotContains(t, config, "mesh-seed-address-port")
+}
+
+func TestRunClusterRejectsNoNodes(t *testing.T) {
+	_, err := RunCluster(context.Background(), 0)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestRunCluster(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	cluster, err := RunCluster(ctx, 3)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoErrorf(t, cluster.Terminate(ctx), "failed to terminate Aerospike cluster")
+	})
+
+	require.Len(t, cluster.Nodes(), 3)
+	for _, node := range cluster.Nodes() {
+		stats, err := node.Stats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "3", stats["cluster_size"])
+		require.NoError(t, node.WaitForClusterSize(ctx, 3, time.Second))
+	}
+
+	seeds, err := cluster.Seeds(ctx)
+	require.NoError(t, err)
+	assert.Len(t, seeds, 3)
+
+	client, err := cluster.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	assert.Len(t, client.GetNodes(), 3)
+
+	key, err := aerospike.NewKey("test", "cluster", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	record, err := client.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, "value", record.Bins["bin"])
+}
+
+func TestSplitClusterOptions(t *testing.T) {
+	shared, perNode, err := splitClusterOptions(2, []testcontainers.ContainerCustomizer{
+		WithNamespace("custom"),
+		WithClusterNode(0, WithRackID("custom", 1)),
+		WithClusterNode(1, WithRackID("custom", 2), WithLogLevel("debug")),
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, shared, 1)
+	require.Len(t, perNode, 2)
+	assert.Len(t, perNode[0], 1)
+	assert.Len(t, perNode[1], 2)
+
+	_, _, err = splitClusterOptions(2, []testcontainers.ContainerCustomizer{WithClusterNode(2, WithRackID("custom", 1))})
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithClusterNodeRejectedByRunContainer(t *testing.T) {
+	_, _, err := newContainerRequest(WithClusterNode(0, WithRackID("test", 1)))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestRunClusterWithRacks(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	cluster, err := RunCluster(ctx, 2,
+		WithClusterNode(0, WithRackID("test", 1)),
+		WithClusterNode(1, WithRackID("test", 2)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoErrorf(t, cluster.Terminate(ctx), "failed to terminate Aerospike cluster")
+	})
+
+	racks, err := cluster.Nodes()[0].AsInfo(ctx, "racks:namespace=test")
+	require.NoError(t, err)
+	assert.Contains(t, racks, "rack_1=")
+	assert.Contains(t, racks, "rack_2=")
+}
+
+func TestDisconnectNetworkSplitsCluster(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	cluster, err := RunCluster(ctx, 2)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoErrorf(t, cluster.Terminate(ctx), "failed to terminate Aerospike cluster")
+	})
+	first, second := cluster.Nodes()[0], cluster.Nodes()[1]
+
+	client, err := second.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "partition", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	require.NoError(t, second.DisconnectNetwork(ctx))
+	require.NoError(t, first.WaitForClusterSize(ctx, 1, 30*time.Second))
+
+	// The node's mapped ports live on the network it left.
+	policy := aerospike.NewPolicy()
+	policy.TotalTimeout = time.Second
+	policy.MaxRetries = 0
+	_, aerr := client.Get(policy, key)
+	require.Error(t, aerr)
+
+	require.NoError(t, second.ConnectNetwork(ctx))
+	require.NoError(t, first.WaitForStableCluster(ctx, 2, time.Minute))
+}
+
+func TestTerminateNodeRejectsInvalidIndex(t *testing.T) {
+	cluster := &Cluster{nodes: []*Container{{}}}
+
+	for _, index := range []int{-1, 1} {
+		require.ErrorIsf(t, cluster.TerminateNode(context.Background(), index), ErrInvalidArgument, "index %d", index)
+	}
+}
+
+func TestTerminateNodeQuiescesFirst(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+	featureKey := featureKeyFile(t)
+
+	ctx := context.Background()
+
+	cluster, err := RunCluster(ctx, 2, WithEnterpriseEdition(), WithFeatureKeyFile(featureKey))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoErrorf(t, cluster.Terminate(ctx), "failed to terminate Aerospike cluster")
+	})
+
+	// With a replication factor of 1 each record lives on one node only, so
+	// none survive the loss of a node unless its partitions moved first.
+	client, err := cluster.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	const records = 200
+	for i := range records {
+		key, err := aerospike.NewKey("test", "quiesce", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"i": i}))
+	}
+
+	require.NoError(t, cluster.TerminateNode(ctx, 1))
+	require.Len(t, cluster.Nodes(), 1)
+
+	remaining := cluster.Nodes()[0]
+	require.NoError(t, remaining.WaitForClusterSize(ctx, 1, time.Minute))
+	count, err := remaining.QueryCount(ctx, "test", "quiesce", nil)
+	require.NoError(t, err)
+	assert.Equal(t, records, count)
+}
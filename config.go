@@ -0,0 +1,252 @@
+package aerospike
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	defaultNamespace = "test"
+	serverConfigPath = "/etc/aerospike/aerospike-testcontainers.conf"
+	dataDir          = "/opt/aerospike/data"
+)
+
+// configEdit changes the rendered aerospike.conf. It receives the final
+// container request so it can validate against the image in use.
+type configEdit func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error
+
+// stanza is one block of an aerospike.conf, such as "service" or
+// "namespace test". Parameters and child stanzas render in insertion order.
+type stanza struct {
+	name     string
+	params   []configParam
+	children []*stanza
+}
+
+type configParam struct {
+	name  string
+	value string
+}
+
+// set sets a parameter, replacing any earlier value for the same name.
+func (s *stanza) set(name, value string) {
+	for i := range s.params {
+		if s.params[i].name == name {
+			s.params[i].value = value
+			return
+		}
+	}
+	s.params = append(s.params, configParam{name: name, value: value})
+}
+
+// add appends a parameter, keeping earlier values for the same name, for
+// parameters that may be repeated such as mesh-seed-address-port.
+func (s *stanza) add(name, value string) {
+	s.params = append(s.params, configParam{name: name, value: value})
+}
+
+// value returns the value of a parameter, or "" if it is not set.
+func (s *stanza) value(name string) string {
+	for _, p := range s.params {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// child returns the child stanza with the given name, creating it if needed.
+func (s *stanza) child(name string) *stanza {
+	for _, c := range s.children {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &stanza{name: name}
+	s.children = append(s.children, c)
+	return c
+}
+
+// removeChildren removes every child stanza whose name starts with prefix.
+func (s *stanza) removeChildren(prefix string) {
+	kept := s.children[:0]
+	for _, c := range s.children {
+		if !strings.HasPrefix(c.name, prefix) {
+			kept = append(kept, c)
+		}
+	}
+	s.children = kept
+}
+
+func (s *stanza) render(b *strings.Builder, depth int) {
+	indent := strings.Repeat("\t", depth)
+	b.WriteString(indent + s.name + " {\n")
+	for _, p := range s.params {
+		b.WriteString(indent + "\t" + p.name + " " + p.value + "\n")
+	}
+	for _, c := range s.children {
+		c.render(b, depth+1)
+	}
+	b.WriteString(indent + "}\n")
+}
+
+// serverConfig is an aerospike.conf generated by the package. The image only
+// exposes a handful of settings through environment variables, so options
+// that need anything else render a complete configuration file instead.
+type serverConfig struct {
+	root stanza
+}
+
+// newServerConfig returns a single-node configuration equivalent to the image
+// defaults, honoring the NAMESPACE and AEROSPIKE_LOG_LEVEL variables set by
+// WithNamespace and WithLogLevel.
+func newServerConfig(req *testcontainers.GenericContainerRequest) *serverConfig {
+	cfg := &serverConfig{}
+
+	cfg.service().set("proto-fd-max", "15000")
+
+	logLevel := "info"
+	if level := req.Env["AEROSPIKE_LOG_LEVEL"]; level != "" {
+		logLevel = level
+	}
+	cfg.root.child("logging").child("console").set("context", "any "+logLevel)
+
+	network := cfg.network()
+	network.child("service").set("address", "any")
+	network.child("service").set("port", "3000")
+	network.child("heartbeat").set("mode", "mesh")
+	network.child("heartbeat").set("address", "local")
+	network.child("heartbeat").set("port", "3002")
+	network.child("heartbeat").set("interval", "150")
+	network.child("heartbeat").set("timeout", "10")
+	network.child("fabric").set("address", "any")
+	network.child("fabric").set("port", "3001")
+	network.child("info").set("address", "any")
+	network.child("info").set("port", "3003")
+
+	namespace := defaultNamespace
+	if ns := req.Env["NAMESPACE"]; ns != "" {
+		namespace = ns
+	}
+	cfg.namespace(namespace)
+
+	return cfg
+}
+
+func (cfg *serverConfig) service() *stanza {
+	return cfg.root.child("service")
+}
+
+func (cfg *serverConfig) network() *stanza {
+	return cfg.root.child("network")
+}
+
+// namespace returns the stanza for the named namespace, creating it with an
+// in-memory storage engine if it does not exist yet.
+func (cfg *serverConfig) namespace(name string) *stanza {
+	ns := cfg.root.child("namespace " + name)
+	if len(ns.params) == 0 && len(ns.children) == 0 {
+		ns.set("replication-factor", "1")
+		ns.set("default-ttl", "0")
+		ns.set("nsup-period", "0")
+		ns.child("storage-engine memory").set("data-size", "1G")
+	}
+	return ns
+}
+
+// namespaces returns the names of the namespaces in the configuration, in the
+// order they were defined.
+func (cfg *serverConfig) namespaces() []string {
+	var names []string
+	for _, c := range cfg.root.children {
+		if name, ok := strings.CutPrefix(c.name, "namespace "); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// storageEngine returns the storage-engine stanza of the named namespace,
+// switching the namespace to the given engine kind ("memory", "device" or
+// "pmem") if it currently uses another one.
+func (cfg *serverConfig) storageEngine(namespace, kind string) *stanza {
+	ns := cfg.namespace(namespace)
+	name := "storage-engine " + kind
+	for _, c := range ns.children {
+		if c.name == name {
+			return c
+		}
+	}
+	ns.removeChildren("storage-engine")
+	return ns.child(name)
+}
+
+// String renders the configuration in aerospike.conf syntax.
+func (cfg *serverConfig) String() string {
+	var b strings.Builder
+	for i, c := range cfg.root.children {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		c.render(&b, 0)
+	}
+	return b.String()
+}
+
+// applyServerConfig renders the configuration produced by the configuration
+// edits in settings into the container and points asd at it, or copies the
+// file set with WithConfigFile instead. It returns the rendered configuration,
+// or nil when no option needs a configuration file, leaving the image
+// defaults in charge, or when the file set with WithConfigFile is used.
+func applyServerConfig(req *testcontainers.GenericContainerRequest, settings options) (*serverConfig, error) {
+	if settings.configFile != "" {
+		if len(settings.configEdits) > 0 {
+			return nil, fmt.Errorf("%w: WithConfigFile cannot be combined with options that render the server configuration", ErrInvalidOption)
+		}
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			HostFilePath:      settings.configFile,
+			ContainerFilePath: serverConfigPath,
+			FileMode:          0o644,
+		})
+		req.Cmd = []string{"asd", "--foreground", "--config-file", serverConfigPath}
+
+		return nil, nil
+	}
+	if len(settings.configEdits) == 0 && settings.servicePort == 0 && !settings.noDefaultNamespace {
+		return nil, nil
+	}
+
+	cfg := newServerConfig(req)
+	if settings.noDefaultNamespace {
+		cfg.root.removeChildren("namespace ")
+	}
+	if settings.servicePort != 0 {
+		cfg.network().child("service").set("port", strconv.Itoa(settings.servicePort))
+	}
+	for _, edit := range settings.configEdits {
+		if err := edit(cfg, req); err != nil {
+			return nil, err
+		}
+	}
+	if len(cfg.namespaces()) == 0 {
+		return nil, fmt.Errorf("%w: WithNoDefaultNamespace requires a namespace defined with WithNamespaces or WithNamespaceConfig", ErrInvalidOption)
+	}
+
+	req.Files = append(req.Files, testcontainers.ContainerFile{
+		Reader:            strings.NewReader(cfg.String()),
+		ContainerFilePath: serverConfigPath,
+		FileMode:          0o644,
+	})
+	req.Cmd = []string{"asd", "--foreground", "--config-file", serverConfigPath}
+
+	return cfg, nil
+}
+
+// isEnterpriseImage reports whether image looks like an enterprise edition
+// image. It is a best-effort check based on the image name.
+func isEnterpriseImage(image string) bool {
+	return strings.Contains(image, "enterprise")
+}
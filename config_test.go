@@ -0,0 +1,185 @@
+package aerospike
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// renderedConfig returns the aerospike.conf that opts would write into the
+// container, failing the test if none is rendered.
+func renderedConfig(t *testing.T, opts ...testcontainers.ContainerCustomizer) string {
+	t.Helper()
+
+	req, _, err := newContainerRequest(opts...)
+	require.NoError(t, err)
+
+	for _, f := range req.Files {
+		if f.ContainerFilePath == serverConfigPath {
+			content, err := io.ReadAll(f.Reader)
+			require.NoError(t, err)
+			return string(content)
+		}
+	}
+
+	require.FailNow(t, "no server config was rendered")
+	return ""
+}
+
+func TestServerConfigNotRenderedByDefault(t *testing.T) {
+	req, _, err := newContainerRequest(WithNamespace("custom"))
+	require.NoError(t, err)
+
+	assert.Empty(t, req.Files)
+	assert.Empty(t, req.Cmd)
+}
+
+func TestServerConfigHonorsEnvOptions(t *testing.T) {
+	conf := renderedConfig(t, WithNamespace("custom"), WithLogLevel("debug"), WithDebugAllocations())
+
+	assert.Contains(t, conf, "namespace custom {")
+	assert.NotContains(t, conf, "namespace test {")
+	assert.Contains(t, conf, "context any debug")
+}
+
+func TestServerConfigPointsAsdAtFile(t *testing.T) {
+	req, _, err := newContainerRequest(WithDebugAllocations())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"asd", "--foreground", "--config-file", serverConfigPath}, req.Cmd)
+}
+
+func TestStanzaRender(t *testing.T) {
+	cfg := &serverConfig{}
+	cfg.service().set("proto-fd-max", "100")
+	cfg.service().set("proto-fd-max", "200")
+	cfg.namespace("ns").set("default-ttl", "60")
+
+	expected := "service {\n" +
+		"\tproto-fd-max 200\n" +
+		"}\n" +
+		"\n" +
+		"namespace ns {\n" +
+		"\treplication-factor 1\n" +
+		"\tdefault-ttl 60\n" +
+		"\tnsup-period 0\n" +
+		"\tstorage-engine memory {\n" +
+		"\t\tdata-size 1G\n" +
+		"\t}\n" +
+		"}\n"
+	assert.Equal(t, expected, cfg.String())
+}
+
+func TestWithDebugAllocations(t *testing.T) {
+	conf := renderedConfig(t, WithEnterpriseEdition(), WithDebugAllocations())
+
+	assert.Contains(t, conf, "debug-allocations all")
+}
+
+func TestWithDebugAllocationsContainer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithDebugAllocations())
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.AsInfo(ctx, "get-config:context=service")
+	require.NoError(t, err)
+	assert.Contains(t, config, "debug-allocations=all")
+}
+
+// minimalConfig is a hand-written single-node aerospike.conf.
+const minimalConfig = `service {
+	proto-fd-max 1024
+}
+
+logging {
+	console {
+		context any info
+	}
+}
+
+network {
+	service {
+		address any
+		port 3000
+	}
+	heartbeat {
+		mode mesh
+		address local
+		port 3002
+		interval 150
+		timeout 10
+	}
+	fabric {
+		port 3001
+	}
+}
+
+namespace handwritten {
+	replication-factor 1
+	storage-engine memory {
+		data-size 512M
+	}
+}
+`
+
+func TestWithConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aerospike.conf")
+	require.NoError(t, os.WriteFile(path, []byte(minimalConfig), 0o600))
+
+	req, _, err := newContainerRequest(WithConfigFile(path))
+	require.NoError(t, err)
+
+	require.Len(t, req.Files, 1)
+	assert.Equal(t, path, req.Files[0].HostFilePath)
+	assert.Equal(t, serverConfigPath, req.Files[0].ContainerFilePath)
+	assert.Equal(t, []string{"asd", "--foreground", "--config-file", serverConfigPath}, req.Cmd)
+}
+
+func TestWithConfigFileValidation(t *testing.T) {
+	dir := t.TempDir()
+
+	_, _, err := newContainerRequest(WithConfigFile(filepath.Join(dir, "missing.conf")))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithConfigFile(dir))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	path := filepath.Join(dir, "aerospike.conf")
+	require.NoError(t, os.WriteFile(path, []byte(minimalConfig), 0o600))
+	_, _, err = newContainerRequest(WithConfigFile(path), WithNamespaces("cache"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithConfigFileStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	path := filepath.Join(t.TempDir(), "aerospike.conf")
+	require.NoError(t, os.WriteFile(path, []byte(minimalConfig), 0o600))
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithConfigFile(path))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("handwritten", "set", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
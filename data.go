@@ -0,0 +1,546 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+)
+
+// truncateTimeout bounds how long Truncate waits for the server to delete the
+// truncated records.
+const truncateTimeout = 30 * time.Second
+
+// scanAllLimit is the most records ScanAll collects before giving up, which
+// keeps an assertion against an unexpectedly large set from exhausting memory.
+const scanAllLimit = 100_000
+
+// ErrTooManyRecords is returned by ScanAll when the set holds more records
+// than it collects.
+var ErrTooManyRecords = errors.New("too many records")
+
+// ChecksumSet returns a stable hex-encoded SHA-256 checksum of every record in
+// the given set. Taking a checksum before and after a restart is a cheap way
+// to assert that data survived intact.
+//
+// Records are ordered by key digest and their bins are serialized canonically
+// (bin names and map keys sorted), so the result depends only on the stored
+// data and not on scan order. Record metadata such as generation and TTL is
+// deliberately excluded because it legitimately changes across restarts.
+func (c Container) ChecksumSet(ctx context.Context, namespace, set string) (string, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return "", err
+	}
+	set = strings.TrimSpace(set)
+
+	records, err := c.snapshotSet(ctx, namespace, set)
+	if err != nil {
+		return "", err
+	}
+
+	digests := make([]string, 0, len(records))
+	for digest := range records {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+
+	hash := sha256.New()
+	for _, digest := range digests {
+		_, _ = hash.Write([]byte(digest))
+		_, _ = hash.Write(records[digest].bins)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DifferenceKind says how a record differs between two sets.
+type DifferenceKind int
+
+const (
+	// Missing means the record is in the first set but not the second.
+	Missing DifferenceKind = iota
+	// Extra means the record is in the second set but not the first.
+	Extra
+	// Changed means the record is in both sets with different bins.
+	Changed
+)
+
+// String returns the name of the difference kind.
+func (k DifferenceKind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case Extra:
+		return "extra"
+	case Changed:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Difference is one record that differs between two sets.
+type Difference struct {
+	Kind DifferenceKind
+	// Key identifies the record. Its user key is only set when the record
+	// was written with SendKey; the digest is always available.
+	Key *aerospike.Key
+	// A and B are the record's bins in each set, nil where it is absent.
+	A aerospike.BinMap
+	B aerospike.BinMap
+}
+
+// CompareSets reports whether namespace.set holds the same records in
+// containers a and b, which turns "did replication or restore work" into a
+// single assertion. Records are matched by key digest, so scan order does not
+// matter, and bins are compared by value. Record metadata such as generation
+// and TTL is ignored.
+//
+// The differences are sorted by key digest. ErrInvalidArgument is returned
+// when a or b is nil.
+func CompareSets(ctx context.Context, a, b *Container, namespace, set string) (bool, []Difference, error) {
+	if a == nil || b == nil {
+		return false, nil, fmt.Errorf("%w: both containers must be set to compare sets", ErrInvalidArgument)
+	}
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return false, nil, err
+	}
+	set = strings.TrimSpace(set)
+
+	recordsA, err := a.snapshotSet(ctx, namespace, set)
+	if err != nil {
+		return false, nil, err
+	}
+	recordsB, err := b.snapshotSet(ctx, namespace, set)
+	if err != nil {
+		return false, nil, err
+	}
+
+	var digests []string
+	for digest := range recordsA {
+		digests = append(digests, digest)
+	}
+	for digest := range recordsB {
+		if _, ok := recordsA[digest]; !ok {
+			digests = append(digests, digest)
+		}
+	}
+	sort.Strings(digests)
+
+	var differences []Difference
+	for _, digest := range digests {
+		recordA, inA := recordsA[digest]
+		recordB, inB := recordsB[digest]
+		switch {
+		case !inB:
+			differences = append(differences, Difference{Kind: Missing, Key: recordA.key, A: recordA.record})
+		case !inA:
+			differences = append(differences, Difference{Kind: Extra, Key: recordB.key, B: recordB.record})
+		case !bytes.Equal(recordA.bins, recordB.bins):
+			differences = append(differences, Difference{Kind: Changed, Key: recordA.key, A: recordA.record, B: recordB.record})
+		}
+	}
+
+	return len(differences) == 0, differences, nil
+}
+
+// DeleteWhere deletes every record in the set that matches filter and returns
+// how many were removed. A nil filter matches every record in the set.
+//
+// The delete runs server side as a background query, and DeleteWhere waits for
+// that task to complete before returning. The count is taken by a query with
+// the same filter just before the delete is issued, so it does not include
+// matching records written concurrently with the delete.
+func (c Container) DeleteWhere(ctx context.Context, namespace, set string, filter *aerospike.Expression) (int, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return 0, err
+	}
+	set = strings.TrimSpace(set)
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := countRecords(ctx, client, namespace, set, filter)
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, nil
+	}
+
+	queryPolicy := aerospike.NewQueryPolicy()
+	queryPolicy.FilterExpression = filter
+
+	task, aerr := client.QueryExecute(queryPolicy, nil, aerospike.NewStatement(namespace, set), aerospike.DeleteOp())
+	if aerr != nil {
+		return 0, fmt.Errorf("failed to start delete on %s.%s: %w", namespace, set, aerr)
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("delete on %s.%s did not complete: %w", namespace, set, ctx.Err())
+	case aerr := <-task.OnComplete():
+		if aerr != nil {
+			return 0, fmt.Errorf("delete on %s.%s failed: %w", namespace, set, aerr)
+		}
+	}
+
+	return deleted, nil
+}
+
+// Truncate removes every record in namespace.set, or in the whole namespace
+// when set is empty, and waits until the server has deleted them. It is much
+// cheaper than starting a new container, so tests can share one container and
+// truncate between them. Records written while Truncate waits keep it waiting
+// until it times out, so nothing else should write to the set meanwhile.
+func (c Container) Truncate(ctx context.Context, namespace, set string) error {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+	set = strings.TrimSpace(set)
+
+	command := "truncate-namespace:namespace=" + namespace
+	target := namespace
+	if set != "" {
+		command = "truncate:namespace=" + namespace + ";set=" + set
+		target += "." + set
+	}
+
+	resp, err := c.AsInfo(ctx, command)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(resp, "ok") {
+		return fmt.Errorf("%w: truncate %s: %s", ErrUnexpectedInfoResponse, target, resp)
+	}
+
+	var objects string
+	err = pollUntil(ctx, truncateTimeout, func(ctx context.Context) (bool, error) {
+		stats, err := c.truncateStats(ctx, namespace, set)
+		if err != nil {
+			return false, err
+		}
+		objects = stats["objects"]
+		return objects == "" || objects == "0", nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for truncation of %s (objects=%s)", err, target, objects)
+	}
+
+	return err
+}
+
+// QueryCount returns how many records in the set match filter without
+// collecting them, which keeps assertions over large sets cheap. A nil filter
+// counts every record in the set.
+func (c Container) QueryCount(ctx context.Context, namespace, set string, filter *aerospike.Expression) (int, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return 0, err
+	}
+	set = strings.TrimSpace(set)
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return countRecords(ctx, client, namespace, set, filter)
+}
+
+// ScanPartitions scans the records of namespace.set held in the count
+// partitions starting at partition begin, using the client's partition
+// filter. Splitting the 4096 partitions into ranges is how parallel and
+// resumable scans shard their work, so tests can check that the ranges
+// together cover the set exactly once.
+func (c Container) ScanPartitions(ctx context.Context, namespace, set string, begin, count int) ([]*aerospike.Record, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return nil, err
+	}
+	set = strings.TrimSpace(set)
+	if begin < 0 || count <= 0 || begin+count > partitionCount {
+		return nil, fmt.Errorf("%w: partition range begin=%d count=%d is outside 0-%d", ErrInvalidArgument, begin, count, partitionCount-1)
+	}
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, aerr := client.ScanPartitions(nil, aerospike.NewPartitionFilterByRange(begin, count), namespace, set)
+	if aerr != nil {
+		return nil, fmt.Errorf("failed to scan partitions %d-%d of %s.%s: %w", begin, begin+count-1, namespace, set, aerr)
+	}
+
+	var records []*aerospike.Record
+	err = forEachRecord(ctx, rs, func(record *aerospike.Record) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan partitions %d-%d of %s.%s: %w", begin, begin+count-1, namespace, set, err)
+	}
+
+	return records, nil
+}
+
+// ScanAll scans namespace.set, or the whole namespace when set is empty, and
+// returns every record in it, in no particular order. It collects at most
+// 100,000 records and returns ErrTooManyRecords for larger sets; use ScanEach
+// to go through those without holding them all in memory.
+func (c Container) ScanAll(ctx context.Context, namespace, set string) ([]*aerospike.Record, error) {
+	var records []*aerospike.Record
+	err := c.ScanEach(ctx, namespace, set, func(record *aerospike.Record) error {
+		if len(records) == scanAllLimit {
+			return fmt.Errorf("%w: more than %d", ErrTooManyRecords, scanAllLimit)
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ScanEach scans namespace.set, or the whole namespace when set is empty, and
+// calls fn for each record as it arrives. An error from fn stops the scan and
+// is returned, wrapped, as are errors reported by the server during the scan.
+// A deadline on ctx bounds the whole scan.
+func (c Container) ScanEach(ctx context.Context, namespace, set string, fn func(*aerospike.Record) error) error {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+	set = strings.TrimSpace(set)
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy := aerospike.NewScanPolicy()
+	if err := applyDeadline(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+
+	rs, aerr := client.ScanAll(policy, namespace, set)
+	if aerr != nil {
+		return fmt.Errorf("failed to scan %s.%s: %w", namespace, set, aerr)
+	}
+	if err := forEachRecord(ctx, rs, fn); err != nil {
+		return fmt.Errorf("failed to scan %s.%s: %w", namespace, set, err)
+	}
+
+	return nil
+}
+
+// truncateStats returns the statistics Truncate watches: those of the set, or
+// of the namespace when set is empty.
+func (c Container) truncateStats(ctx context.Context, namespace, set string) (map[string]string, error) {
+	if set == "" {
+		return c.NamespaceStats(ctx, namespace)
+	}
+
+	resp, err := c.AsInfo(ctx, "sets/"+namespace+"/"+set)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInfoPairs(resp, ":"), nil
+}
+
+// setEntry is one record captured by snapshotSet.
+type setEntry struct {
+	key    *aerospike.Key
+	record aerospike.BinMap
+	// bins is the canonical encoding of record, see writeCanonical.
+	bins []byte
+}
+
+// snapshotSet scans namespace.set and returns its records keyed by digest.
+func (c Container) snapshotSet(ctx context.Context, namespace, set string) (map[string]setEntry, error) {
+	client, err := c.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, aerr := client.ScanAll(nil, namespace, set)
+	if aerr != nil {
+		return nil, fmt.Errorf("failed to scan %s.%s: %w", namespace, set, aerr)
+	}
+
+	records := make(map[string]setEntry)
+	err = forEachRecord(ctx, rs, func(record *aerospike.Record) error {
+		var buf bytes.Buffer
+		writeCanonical(&buf, map[string]any(record.Bins))
+		records[string(record.Key.Digest())] = setEntry{key: record.Key, record: record.Bins, bins: buf.Bytes()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s.%s: %w", namespace, set, err)
+	}
+
+	return records, nil
+}
+
+// countRecords counts the records in the set that match filter without
+// fetching their bins.
+func countRecords(ctx context.Context, client *aerospike.Client, namespace, set string, filter *aerospike.Expression) (int, error) {
+	queryPolicy := aerospike.NewQueryPolicy()
+	queryPolicy.FilterExpression = filter
+	queryPolicy.IncludeBinData = false
+
+	rs, aerr := client.Query(queryPolicy, aerospike.NewStatement(namespace, set))
+	if aerr != nil {
+		return 0, fmt.Errorf("failed to query %s.%s: %w", namespace, set, aerr)
+	}
+
+	count := 0
+	err := forEachRecord(ctx, rs, func(*aerospike.Record) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s.%s: %w", namespace, set, err)
+	}
+
+	return count, nil
+}
+
+// forEachRecord drains rs, calling fn for each record until the recordset is
+// exhausted, fn or the scan fails, or ctx is done. The recordset is always
+// closed, which also cancels any scan still in flight.
+func forEachRecord(ctx context.Context, rs *aerospike.Recordset, fn func(*aerospike.Record) error) error {
+	defer func() { _ = rs.Close() }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case res, ok := <-rs.Results():
+			if !ok {
+				return nil
+			}
+			if res.Err != nil {
+				return res.Err
+			}
+			if err := fn(res.Record); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeCanonical appends a deterministic, type-tagged encoding of v to buf.
+// Every value is length-prefixed so that adjacent values cannot be confused,
+// and maps are emitted in the order of their encoded keys.
+func writeCanonical(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("n;")
+	case bool:
+		buf.WriteString("t" + strconv.FormatBool(v) + ";")
+	case int:
+		buf.WriteString("i" + strconv.FormatInt(int64(v), 10) + ";")
+	case int64:
+		buf.WriteString("i" + strconv.FormatInt(v, 10) + ";")
+	case float64:
+		buf.WriteString("f" + strconv.FormatUint(math.Float64bits(v), 16) + ";")
+	case string:
+		buf.WriteString("s" + strconv.Itoa(len(v)) + ":" + v)
+	case []byte:
+		buf.WriteString("b" + strconv.Itoa(len(v)) + ":")
+		buf.Write(v)
+	case aerospike.GeoJSONValue:
+		buf.WriteString("g" + strconv.Itoa(len(v)) + ":" + string(v))
+	case aerospike.HLLValue:
+		buf.WriteString("h" + strconv.Itoa(len(v)) + ":")
+		buf.Write(v)
+	case []any:
+		buf.WriteString("l" + strconv.Itoa(len(v)) + ":")
+		for _, elem := range v {
+			writeCanonical(buf, elem)
+		}
+	case map[string]any:
+		entries := make(map[any]any, len(v))
+		for key, value := range v {
+			entries[key] = value
+		}
+		writeCanonicalMap(buf, entries)
+	case map[any]any:
+		writeCanonicalMap(buf, v)
+	default:
+		// Fall back on reflection for the less common integer widths and
+		// typed collections the client may hand back.
+		writeCanonicalReflect(buf, v)
+	}
+}
+
+func writeCanonicalMap(buf *bytes.Buffer, m map[any]any) {
+	type pair struct {
+		key   []byte
+		value any
+	}
+	pairs := make([]pair, 0, len(m))
+	for key, value := range m {
+		var keyBuf bytes.Buffer
+		writeCanonical(&keyBuf, key)
+		pairs = append(pairs, pair{key: keyBuf.Bytes(), value: value})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].key, pairs[j].key) < 0
+	})
+
+	buf.WriteString("m" + strconv.Itoa(len(pairs)) + ":")
+	for _, p := range pairs {
+		buf.Write(p.key)
+		writeCanonical(buf, p.value)
+	}
+}
+
+func writeCanonicalReflect(buf *bytes.Buffer, v any) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() { //nolint:exhaustive // everything else is handled by the default case
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		writeCanonical(buf, rv.Int())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		buf.WriteString("u" + strconv.FormatUint(rv.Uint(), 10) + ";")
+	case reflect.Float32:
+		writeCanonical(buf, rv.Float())
+	case reflect.Slice, reflect.Array:
+		elems := make([]any, rv.Len())
+		for i := range elems {
+			elems[i] = rv.Index(i).Interface()
+		}
+		writeCanonical(buf, elems)
+	case reflect.Map:
+		entries := make(map[any]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries[iter.Key().Interface()] = iter.Value().Interface()
+		}
+		writeCanonicalMap(buf, entries)
+	default:
+		s := fmt.Sprintf("%T:%v", v, v)
+		buf.WriteString("x" + strconv.Itoa(len(s)) + ":" + s)
+	}
+}
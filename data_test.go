@@ -0,0 +1,388 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func canonical(v any) []byte {
+	var buf bytes.Buffer
+	writeCanonical(&buf, v)
+	return buf.Bytes()
+}
+
+func TestWriteCanonicalIsOrderIndependent(t *testing.T) {
+	a := map[any]any{"a": 1, "b": []any{"x", 2.5}, "c": map[any]any{1: "one", 2: "two"}}
+	b := map[any]any{"c": map[any]any{2: "two", 1: "one"}, "b": []any{"x", 2.5}, "a": 1}
+
+	assert.Equal(t, canonical(a), canonical(b))
+}
+
+func TestWriteCanonicalDistinguishesValues(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b any
+	}{
+		{name: "string vs int", a: "1", b: 1},
+		{name: "int vs float", a: 1, b: 1.0},
+		{name: "string vs bytes", a: "ab", b: []byte("ab")},
+		{name: "adjacent strings", a: []any{"ab", "c"}, b: []any{"a", "bc"}},
+		{name: "list order", a: []any{1, 2}, b: []any{2, 1}},
+		{name: "map value", a: map[any]any{"k": 1}, b: map[any]any{"k": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEqual(t, canonical(tt.a), canonical(tt.b))
+		})
+	}
+}
+
+func TestWriteCanonicalNormalizesIntegerWidths(t *testing.T) {
+	assert.Equal(t, canonical(int64(42)), canonical(int32(42)))
+	assert.Equal(t, canonical(42), canonical(int64(42)))
+}
+
+func TestChecksumSet(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	for i := range 20 {
+		key, err := aerospike.NewKey("test", "checksum", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{
+			"id":   i,
+			"name": "record",
+			"tags": map[any]any{"a": i, "b": []any{1, 2, 3}},
+		}))
+	}
+
+	first, err := container.ChecksumSet(ctx, "test", "checksum")
+	require.NoError(t, err)
+	second, err := container.ChecksumSet(ctx, "test", "checksum")
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "checksum should be stable across scans")
+
+	key, err := aerospike.NewKey("test", "checksum", 7)
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"name": "changed"}))
+
+	changed, err := container.ChecksumSet(ctx, "test", "checksum")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed, "checksum should change when data changes")
+}
+
+func TestDeleteWhere(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	for i := range 10 {
+		key, err := aerospike.NewKey("test", "delete-where", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"n": i}))
+	}
+
+	filter := aerospike.ExpGreaterEq(aerospike.ExpIntBin("n"), aerospike.ExpIntVal(5))
+	deleted, err := container.DeleteWhere(ctx, "test", "delete-where", filter)
+	require.NoError(t, err)
+	assert.Equal(t, 5, deleted)
+
+	for i := range 10 {
+		key, err := aerospike.NewKey("test", "delete-where", i)
+		require.NoError(t, err)
+		exists, err := client.Exists(nil, key)
+		require.NoError(t, err)
+		assert.Equalf(t, i < 5, exists, "unexpected existence for record %d", i)
+	}
+}
+
+func TestQueryCount(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	for i := range 30 {
+		status := "inactive"
+		if i%3 == 0 {
+			status = "active"
+		}
+		key, err := aerospike.NewKey("test", "query-count", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"status": status}))
+	}
+
+	active, err := container.QueryCount(ctx, "test", "query-count",
+		aerospike.ExpEq(aerospike.ExpStringBin("status"), aerospike.ExpStringVal("active")))
+	require.NoError(t, err)
+	assert.Equal(t, 10, active)
+
+	all, err := container.QueryCount(ctx, "test", "query-count", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 30, all)
+}
+
+func TestTruncateCommands(t *testing.T) {
+	tests := []struct {
+		set     string
+		command string
+		stats   string
+	}{
+		{set: "users", command: "truncate:namespace=test;set=users", stats: "sets/test/users"},
+		{set: "", command: "truncate-namespace:namespace=test", stats: "namespace/test"},
+	}
+
+	for _, tt := range tests {
+		var commands []string
+		c := Container{
+			Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+				command := cmd[len(cmd)-1]
+				commands = append(commands, command)
+				if strings.HasPrefix(command, "truncate") {
+					return 0, "ok", nil
+				}
+				if strings.HasPrefix(command, "sets/") {
+					return 0, "objects=0:tombstones=0:truncate_lut=1000", nil
+				}
+				return 0, "objects=0;tombstones=0", nil
+			}},
+			settings: defaultOptions(),
+		}
+
+		require.NoError(t, c.Truncate(context.Background(), "test", tt.set))
+		assert.Equal(t, []string{tt.command, tt.stats}, commands)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	records := make([]SeedRecord, 50)
+	for i := range records {
+		records[i] = SeedRecord{Key: i, Bins: aerospike.BinMap{"n": i}}
+	}
+	require.NoError(t, container.Seed(ctx, "test", "truncate", records))
+	require.NoError(t, container.Seed(ctx, "test", "kept", records))
+
+	require.NoError(t, container.Truncate(ctx, "test", "truncate"))
+
+	count, err := container.QueryCount(ctx, "test", "truncate", nil)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	count, err = container.QueryCount(ctx, "test", "kept", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 50, count)
+
+	require.NoError(t, container.Truncate(ctx, "test", ""))
+
+	count, err = container.QueryCount(ctx, "test", "kept", nil)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestScanAllRejectsEmptyNamespace(t *testing.T) {
+	var c Container
+
+	_, err := c.ScanAll(context.Background(), " ", "set")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestScanAll(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	records := make([]SeedRecord, 120)
+	for i := range records {
+		records[i] = SeedRecord{Key: i, Bins: aerospike.BinMap{"i": i}}
+	}
+	require.NoError(t, container.Seed(ctx, "test", "scan-all", records))
+
+	scanned, err := container.ScanAll(ctx, "test", "scan-all")
+	require.NoError(t, err)
+	assert.Len(t, scanned, len(records))
+
+	errStop := errors.New("stop")
+	calls := 0
+	err = container.ScanEach(ctx, "test", "scan-all", func(*aerospike.Record) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestScanPartitionsRejectsInvalidRange(t *testing.T) {
+	var c Container
+
+	for _, r := range [][2]int{{-1, 1}, {0, 0}, {4000, 97}} {
+		_, err := c.ScanPartitions(context.Background(), "test", "set", r[0], r[1])
+		require.ErrorIsf(t, err, ErrInvalidArgument, "begin=%d count=%d", r[0], r[1])
+	}
+}
+
+func TestScanPartitions(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	for i := range 50 {
+		key, err := aerospike.NewKey("test", "scan-partitions", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"i": i}))
+	}
+
+	// Four shards together must cover every record exactly once.
+	seen := make(map[string]bool)
+	const shard = partitionCount / 4
+	for begin := 0; begin < partitionCount; begin += shard {
+		records, err := container.ScanPartitions(ctx, "test", "scan-partitions", begin, shard)
+		require.NoError(t, err)
+		for _, record := range records {
+			id := record.Key.PartitionId()
+			assert.GreaterOrEqual(t, id, begin)
+			assert.Less(t, id, begin+shard)
+
+			digest := string(record.Key.Digest())
+			assert.Falsef(t, seen[digest], "record %v scanned twice", record.Key)
+			seen[digest] = true
+		}
+	}
+	assert.Len(t, seen, 50)
+}
+
+func TestDifferenceKindString(t *testing.T) {
+	assert.Equal(t, "missing", Missing.String())
+	assert.Equal(t, "extra", Extra.String())
+	assert.Equal(t, "changed", Changed.String())
+	assert.Equal(t, "unknown", DifferenceKind(42).String())
+}
+
+func TestCompareSetsRejectsNilContainer(t *testing.T) {
+	c := &Container{}
+	for _, pair := range [][2]*Container{{nil, c}, {c, nil}, {nil, nil}} {
+		_, _, err := CompareSets(context.Background(), pair[0], pair[1], "test", "compare")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	}
+}
+
+func TestCompareSets(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	containerA := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, containerA.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+	containerB := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, containerB.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	clientFor := func(c *Container) *aerospike.Client {
+		host, err := c.Host(ctx)
+		require.NoErrorf(t, err, "failed to fetch Aerospike host")
+		port, err := c.ServicePort(ctx)
+		require.NoErrorf(t, err, "failed to fetch Aerospike port")
+		return newAerospikeClient(t, host, port)
+	}
+	clientA, clientB := clientFor(containerA), clientFor(containerB)
+
+	put := func(client *aerospike.Client, id string, bins aerospike.BinMap) {
+		key, err := aerospike.NewKey("test", "compare", id)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, bins))
+	}
+	for _, client := range []*aerospike.Client{clientA, clientB} {
+		put(client, "same-1", aerospike.BinMap{"n": 1})
+		put(client, "same-2", aerospike.BinMap{"n": 2})
+	}
+
+	equal, differences, err := CompareSets(ctx, containerA, containerB, "test", "compare")
+	require.NoError(t, err)
+	assert.True(t, equal)
+	assert.Empty(t, differences)
+
+	put(clientA, "only-a", aerospike.BinMap{"n": 3})
+	put(clientB, "only-b", aerospike.BinMap{"n": 4})
+	put(clientB, "same-2", aerospike.BinMap{"n": 20})
+
+	equal, differences, err = CompareSets(ctx, containerA, containerB, "test", "compare")
+	require.NoError(t, err)
+	assert.False(t, equal)
+
+	kinds := make(map[DifferenceKind]int)
+	for _, d := range differences {
+		kinds[d.Kind]++
+	}
+	assert.Equal(t, map[DifferenceKind]int{Missing: 1, Extra: 1, Changed: 1}, kinds)
+}
@@ -0,0 +1,46 @@
+package aerospike
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// featureKeyPath is where WithFeatureKeyFile places the feature key file.
+const featureKeyPath = "/etc/aerospike/features.conf"
+
+// WithFeatureKeyFile copies the enterprise feature key file at path into the
+// container and points the server at it. Enterprise-only features such as
+// strong consistency and compression refuse to start without a key that
+// enables them. The file is read when the option is applied, so a missing or
+// empty file is reported before any container is created.
+//
+// The setting is static, so this renders a server configuration file in
+// place of the image defaults.
+func WithFeatureKeyFile(path string) Option {
+	return func(o *options) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%w: failed to read feature key file: %w", ErrInvalidOption, err)
+		}
+		if len(content) == 0 {
+			return fmt.Errorf("%w: feature key file %q is empty", ErrInvalidOption, path)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				return fmt.Errorf("%w: feature keys require an enterprise image, got %q", ErrInvalidOption, req.Image)
+			}
+			req.Files = append(req.Files, testcontainers.ContainerFile{
+				HostFilePath:      path,
+				ContainerFilePath: featureKeyPath,
+				FileMode:          0o644,
+			})
+			cfg.service().set("feature-key-file", featureKeyPath)
+			return nil
+		})
+
+		return nil
+	}
+}
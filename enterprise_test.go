@@ -0,0 +1,81 @@
+package aerospike
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// featureKeyEnv names the environment variable that points the enterprise
+// tests at a feature key file. Tests that need one are skipped without it.
+const featureKeyEnv = "AEROSPIKE_FEATURE_KEY_FILE"
+
+// featureKeyFile returns the feature key file to use, skipping the test when
+// none is configured.
+func featureKeyFile(t *testing.T) string {
+	t.Helper()
+
+	path := os.Getenv(featureKeyEnv)
+	if path == "" {
+		t.Skipf("%s is not set", featureKeyEnv)
+	}
+
+	return path
+}
+
+func TestWithFeatureKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "features.conf")
+	require.NoError(t, os.WriteFile(path, []byte("feature-key-version 2\n"), 0o600))
+
+	req, _, err := newContainerRequest(WithEnterpriseEdition(), WithFeatureKeyFile(path))
+	require.NoError(t, err)
+
+	var copied bool
+	for _, f := range req.Files {
+		if f.ContainerFilePath == featureKeyPath {
+			copied = true
+			assert.Equal(t, path, f.HostFilePath)
+		}
+	}
+	assert.True(t, copied, "feature key file should be copied into the container")
+
+	conf := renderedConfig(t, WithEnterpriseEdition(), WithFeatureKeyFile(path))
+	assert.Contains(t, conf, "\tfeature-key-file /etc/aerospike/features.conf\n")
+}
+
+func TestWithFeatureKeyFileValidation(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.conf")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	valid := filepath.Join(dir, "features.conf")
+	require.NoError(t, os.WriteFile(valid, []byte("feature-key-version 2\n"), 0o600))
+
+	_, _, err := newContainerRequest(WithEnterpriseEdition(), WithFeatureKeyFile(filepath.Join(dir, "missing.conf")))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithFeatureKeyFile(empty))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithFeatureKeyFile(valid))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithFeatureKeyFileStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+	path := featureKeyFile(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithFeatureKeyFile(path))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	resp, err := container.AsInfo(ctx, "get-config:context=service")
+	require.NoError(t, err)
+	assert.Contains(t, resp, "feature-key-file="+featureKeyPath)
+}
@@ -0,0 +1,88 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// execResult is the outcome of a command run with runExec.
+type execResult struct {
+	exitCode int
+	stdout   string
+	stderr   string
+}
+
+// runExec runs cmd in the given container and returns its exit code and its
+// standard output and error, separated. Exec keeps reading the attached
+// stream until the process exits regardless of the context, so it runs in its
+// own goroutine and is abandoned once ctx is done.
+func runExec(ctx context.Context, c testcontainers.Container, cmd []string) (execResult, error) {
+	type outcome struct {
+		result execResult
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		exitCode, reader, err := c.Exec(ctx, cmd)
+		if err != nil {
+			done <- outcome{err: err}
+			return
+		}
+		var stdout, stderr bytes.Buffer
+		if _, err := stdcopy.StdCopy(&stdout, &stderr, reader); err != nil {
+			done <- outcome{err: err}
+			return
+		}
+		done <- outcome{result: execResult{exitCode: exitCode, stdout: stdout.String(), stderr: stderr.String()}}
+	}()
+
+	var o outcome
+	select {
+	case <-ctx.Done():
+		return execResult{}, fmt.Errorf("%s did not complete: %w", describeCommand(cmd), ctx.Err())
+	case o = <-done:
+	}
+
+	if o.err != nil {
+		return execResult{}, fmt.Errorf("failed to run %s: %w", describeCommand(cmd), o.err)
+	}
+
+	return o.result, nil
+}
+
+// requireTool returns ErrToolNotAvailable unless the named Aerospike tool is
+// installed in the container image.
+func (c Container) requireTool(ctx context.Context, tool string) error {
+	lookup, err := runExec(ctx, c.Container, []string{"sh", "-c", "command -v " + tool})
+	if err != nil {
+		return err
+	}
+	if lookup.exitCode != 0 {
+		return fmt.Errorf("%w: %s is not installed in image", ErrToolNotAvailable, tool)
+	}
+
+	return nil
+}
+
+// describeCommand renders cmd for error messages, masking the value of any
+// -P or --password flag.
+func describeCommand(cmd []string) string {
+	masked := make([]string, len(cmd))
+	for i, arg := range cmd {
+		switch {
+		case i > 0 && cmd[i-1] == "-P":
+			arg = "***"
+		case strings.HasPrefix(arg, "--password="):
+			arg = "--password=***"
+		}
+		masked[i] = arg
+	}
+
+	return fmt.Sprintf("%q", strings.Join(masked, " "))
+}
@@ -0,0 +1,221 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	defaultInfoTimeout = 10 * time.Second
+	infoRetryBackoff   = 100 * time.Millisecond
+)
+
+// ErrInfoCommandFailed is returned when asinfo exits with a non-zero status.
+var ErrInfoCommandFailed = errors.New("asinfo command failed")
+
+// AsInfo runs "asinfo -v <command>" inside the container and returns the
+// trimmed response. Each attempt is bounded by the timeout set with
+// WithInfoTimeout, and transient failures are retried as configured with
+// WithInfoRetries.
+func (c Container) AsInfo(ctx context.Context, command string) (string, error) {
+	return execInfo(ctx, c.Container, c.settings, command)
+}
+
+// AsInfoNonEmpty is like AsInfo but also retries an empty response as
+// configured with WithInfoRetries, for commands whose answer is never empty
+// once the server is up, such as "statistics". When the retries run out, the
+// empty response is returned without an error.
+func (c Container) AsInfoNonEmpty(ctx context.Context, command string) (string, error) {
+	return retryInfo(ctx, c.Container, c.settings, command, true)
+}
+
+// ListNamespaces returns the namespaces the server knows about, sorted by name.
+func (c Container) ListNamespaces(ctx context.Context) ([]string, error) {
+	resp, err := c.AsInfoNonEmpty(ctx, "namespaces")
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []string
+	for _, ns := range strings.Split(resp, ";") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// ServerVersion describes the build of the running server.
+type ServerVersion struct {
+	// Major, Minor, Patch and Build are the numeric components of the build,
+	// such as 8, 0, 0 and 1 for "8.0.0.1". Missing components are zero.
+	Major int
+	Minor int
+	Patch int
+	Build int
+	// Edition is the edition reported by the server, such as
+	// "Aerospike Community Edition".
+	Edition string
+	// Raw is the build string as reported by the server.
+	Raw string
+}
+
+// String returns the build string as reported by the server.
+func (v ServerVersion) String() string {
+	return v.Raw
+}
+
+// IsEnterprise reports whether the server is an enterprise edition build.
+func (v ServerVersion) IsEnterprise() bool {
+	return strings.Contains(strings.ToLower(v.Edition), "enterprise")
+}
+
+// AtLeast reports whether the version is major.minor or later, for tests that
+// depend on a feature introduced in a given release.
+func (v ServerVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// Version returns the build and edition of the running server, as reported by
+// the "build" and "edition" info commands.
+func (c Container) Version(ctx context.Context) (ServerVersion, error) {
+	build, err := c.AsInfoNonEmpty(ctx, "build")
+	if err != nil {
+		return ServerVersion{}, err
+	}
+	parts := parseVersion(build)
+	if len(parts) == 0 {
+		return ServerVersion{}, fmt.Errorf("%w: build %q is not a version", ErrUnexpectedInfoResponse, build)
+	}
+	edition, err := c.AsInfoNonEmpty(ctx, "edition")
+	if err != nil {
+		return ServerVersion{}, err
+	}
+
+	version := ServerVersion{Edition: edition, Raw: build}
+	for i, dst := range []*int{&version.Major, &version.Minor, &version.Patch, &version.Build} {
+		if i < len(parts) {
+			*dst = parts[i]
+		}
+	}
+
+	return version, nil
+}
+
+// serverVersion returns the numeric components of the server build, such as
+// [8 0 0 1].
+func (c Container) serverVersion(ctx context.Context) ([]int, error) {
+	build, err := c.AsInfoNonEmpty(ctx, "build")
+	if err != nil {
+		return nil, err
+	}
+
+	version := parseVersion(build)
+	if len(version) == 0 {
+		return nil, fmt.Errorf("%w: build %q is not a version", ErrUnexpectedInfoResponse, build)
+	}
+
+	return version, nil
+}
+
+// execInfo runs asinfo for command in the given container, retrying transient
+// failures up to settings.infoRetries times with a linear backoff.
+func execInfo(ctx context.Context, c testcontainers.Container, settings options, command string) (string, error) {
+	return retryInfo(ctx, c, settings, command, false)
+}
+
+// retryInfo implements execInfo. With nonEmpty set, an empty response is
+// retried like a transient failure.
+func retryInfo(ctx context.Context, c testcontainers.Container, settings options, command string, nonEmpty bool) (string, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := runInfo(ctx, c, settings, command)
+		transient := isTransientInfoError(err) || (nonEmpty && err == nil && resp == "")
+		if attempt >= settings.infoRetries || !transient {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return resp, err
+		case <-time.After(time.Duration(attempt+1) * infoRetryBackoff):
+		}
+	}
+}
+
+// isTransientInfoError reports whether an asinfo failure looks like a blip
+// worth retrying: a timed-out attempt or a failure to reach the server, as
+// happens right after startup or during migrations. Genuine command errors
+// are not retried, and neither are successful commands, as an empty response
+// is a valid answer to many of them, such as sindex-list without indexes.
+func isTransientInfoError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	if !errors.Is(err, ErrInfoCommandFailed) {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"connect", "timed out", "timeout"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runInfo runs asinfo for command in the given container once and gives up
+// once settings.infoTimeout elapses. When security is enabled, asinfo
+// authenticates with the credentials set with WithSecurity.
+func runInfo(ctx context.Context, c testcontainers.Container, settings options, command string) (string, error) {
+	return runInfoAt(ctx, c, settings, "", settings.servicePort, command)
+}
+
+// runInfoAt is like runInfo but asks the node at host and port, such as a
+// peer in the same cluster. An empty host and a zero port select the asinfo
+// defaults, the local server on port 3000.
+func runInfoAt(ctx context.Context, c testcontainers.Container, settings options, host string, port int, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, settings.infoTimeout)
+	defer cancel()
+
+	cmd := []string{"asinfo"}
+	if host != "" {
+		cmd = append(cmd, "-h", host)
+	}
+	if port != 0 {
+		cmd = append(cmd, "-p", strconv.Itoa(port))
+	}
+	if settings.user != "" {
+		cmd = append(cmd, "-U", settings.user, "-P", settings.password)
+	}
+	cmd = append(cmd, "-v", command)
+
+	result, err := runExec(ctx, c, cmd)
+	if err != nil {
+		return "", err
+	}
+
+	if result.exitCode != 0 {
+		// asinfo reports most failures on stderr, but some versions use stdout.
+		message := strings.TrimSpace(result.stderr)
+		if message == "" {
+			message = strings.TrimSpace(result.stdout)
+		}
+		return "", fmt.Errorf("%w: %q exited with code %d: %s", ErrInfoCommandFailed, command, result.exitCode, message)
+	}
+
+	return strings.TrimSpace(result.stdout), nil
+}
@@ -0,0 +1,420 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+)
+
+// fakeContainer stubs out Exec, State and ContainerIP, and optionally Logs and
+// file copies, so the asinfo helpers can be exercised without Docker. The
+// output of exec is returned on stdout and stderr is returned on stderr,
+// framed the way Docker multiplexes them. State reports state, or a running
+// container when it is nil, and ContainerIP reports ip. Copied files are kept
+// in files, which must be non-nil to copy into the container. Calling any
+// other testcontainers.Container method panics.
+type fakeContainer struct {
+	testcontainers.Container
+
+	exec   func(ctx context.Context, cmd []string) (int, string, error)
+	stderr string
+	logs   func() string
+	files  map[string][]byte
+	state  *container.State
+	ip     string
+	// networks maps the networks of the container to its aliases there. The
+	// container is on the default bridge network when it is nil.
+	networks map[string][]string
+}
+
+func (f *fakeContainer) Exec(ctx context.Context, cmd []string, _ ...tcexec.ProcessOption) (int, io.Reader, error) {
+	exitCode, output, err := f.exec(ctx, cmd)
+
+	var stream bytes.Buffer
+	for _, frame := range []struct {
+		kind    stdcopy.StdType
+		payload string
+	}{{stdcopy.Stdout, output}, {stdcopy.Stderr, f.stderr}} {
+		if frame.payload == "" {
+			continue
+		}
+		header := make([]byte, 8)
+		header[0] = byte(frame.kind)
+		binary.BigEndian.PutUint32(header[4:], uint32(len(frame.payload)))
+		stream.Write(header)
+		stream.WriteString(frame.payload)
+	}
+
+	return exitCode, &stream, err
+}
+
+func (f *fakeContainer) State(context.Context) (*container.State, error) {
+	if f.state == nil {
+		return &container.State{Running: true, Status: container.StateRunning}, nil
+	}
+	return f.state, nil
+}
+
+func (f *fakeContainer) ContainerIP(context.Context) (string, error) {
+	return f.ip, nil
+}
+
+func (f *fakeContainer) Networks(context.Context) ([]string, error) {
+	if f.networks == nil {
+		return []string{"bridge"}, nil
+	}
+	names := make([]string, 0, len(f.networks))
+	for name := range f.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f *fakeContainer) NetworkAliases(context.Context) (map[string][]string, error) {
+	return f.networks, nil
+}
+
+func (f *fakeContainer) Name(context.Context) (string, error) {
+	return "/aerospike-node", nil
+}
+
+func (f *fakeContainer) Logs(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.logs())), nil
+}
+
+func (f *fakeContainer) CopyToContainer(_ context.Context, content []byte, path string, _ int64) error {
+	f.files[path] = content
+	return nil
+}
+
+func (f *fakeContainer) CopyFileFromContainer(_ context.Context, path string) (io.ReadCloser, error) {
+	content, ok := f.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func TestAsInfoReturnsTrimmedResponse(t *testing.T) {
+	var gotCmd []string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			gotCmd = cmd
+			return 0, "8.0.0.1\n", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	resp, err := c.AsInfo(context.Background(), "build")
+	require.NoError(t, err)
+
+	assert.Equal(t, "8.0.0.1", resp)
+	assert.Equal(t, []string{"asinfo", "-v", "build"}, gotCmd)
+}
+
+func TestAsInfoNonZeroExitCode(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			return 1, "connection refused", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	_, err := c.AsInfo(context.Background(), "build")
+	require.ErrorIs(t, err, ErrInfoCommandFailed)
+	assert.Contains(t, err.Error(), "connection refused")
+}
+
+func TestAsInfoReportsStderr(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{
+			exec: func(context.Context, []string) (int, string, error) {
+				return 255, "partial output", nil
+			},
+			stderr: "Error: -1, Failed to connect\n",
+		},
+		settings: defaultOptions(),
+	}
+
+	_, err := c.AsInfo(context.Background(), "build")
+	require.ErrorIs(t, err, ErrInfoCommandFailed)
+	assert.Contains(t, err.Error(), "Failed to connect")
+	assert.NotContains(t, err.Error(), "partial output")
+}
+
+func TestAsInfoIgnoresStderrOnSuccess(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{
+			exec: func(context.Context, []string) (int, string, error) {
+				return 0, "8.0.0.1\n", nil
+			},
+			stderr: "warning: deprecated flag\n",
+		},
+		settings: defaultOptions(),
+	}
+
+	resp, err := c.AsInfo(context.Background(), "build")
+	require.NoError(t, err)
+	assert.Equal(t, "8.0.0.1", resp)
+}
+
+func TestAsInfoHonorsInfoTimeout(t *testing.T) {
+	settings := defaultOptions()
+	require.NoError(t, WithInfoTimeout(50*time.Millisecond)(&settings))
+
+	c := Container{
+		Container: &fakeContainer{exec: func(ctx context.Context, _ []string) (int, string, error) {
+			// Simulate a wedged server that never answers.
+			<-ctx.Done()
+			time.Sleep(time.Second)
+			return 0, "", nil
+		}},
+		settings: settings,
+	}
+
+	start := time.Now()
+	_, err := c.AsInfo(context.Background(), "statistics")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWithInfoTimeoutRejectsNonPositive(t *testing.T) {
+	settings := defaultOptions()
+
+	err := WithInfoTimeout(0)(&settings)
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Equal(t, defaultInfoTimeout, settings.infoTimeout)
+}
+
+func TestListNamespaces(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			return 0, "test;cache;analytics;\n", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	namespaces, err := c.ListNamespaces(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analytics", "cache", "test"}, namespaces)
+}
+
+func TestNamespaceArgumentsAreNormalized(t *testing.T) {
+	var gotCmd []string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			gotCmd = cmd
+			return 0, "objects=0", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	_, err := c.NamespaceStats(context.Background(), "  test\t")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asinfo", "-v", "namespace/test"}, gotCmd)
+
+	_, err = c.NamespaceStats(context.Background(), " ")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	_, err = c.PartitionMap(context.Background(), "")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestAsInfoRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []string
+		exitCodes []int
+		wantCalls int
+		wantResp  string
+		wantErr   error
+	}{
+		{
+			name:      "empty response is returned as is",
+			responses: []string{"", "ok"},
+			exitCodes: []int{0, 0},
+			wantCalls: 1,
+			wantResp:  "",
+		},
+		{
+			name:      "connection failure is retried",
+			responses: []string{"Failed to connect to 127.0.0.1:3000", "ok"},
+			exitCodes: []int{1, 0},
+			wantCalls: 2,
+			wantResp:  "ok",
+		},
+		{
+			name:      "command error is not retried",
+			responses: []string{"ERROR::unrecognized command", "ok"},
+			exitCodes: []int{1, 0},
+			wantCalls: 1,
+			wantErr:   ErrInfoCommandFailed,
+		},
+		{
+			name:      "retries are bounded",
+			responses: []string{"Failed to connect", "Failed to connect", "Failed to connect", "Failed to connect", "ok"},
+			exitCodes: []int{1, 1, 1, 1, 0},
+			wantCalls: 4,
+			wantErr:   ErrInfoCommandFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := defaultOptions()
+			require.NoError(t, WithInfoRetries(3)(&settings))
+
+			calls := 0
+			c := Container{
+				Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+					i := calls
+					calls++
+					return tt.exitCodes[i], tt.responses[i], nil
+				}},
+				settings: settings,
+			}
+
+			resp, err := c.AsInfo(context.Background(), "sindex-list:ns=test")
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantResp, resp)
+		})
+	}
+}
+
+func TestAsInfoNonEmptyRetriesEmptyResponses(t *testing.T) {
+	settings := defaultOptions()
+	require.NoError(t, WithInfoRetries(3)(&settings))
+
+	responses := []string{"", "", "cluster_size=1"}
+	calls := 0
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			resp := responses[calls]
+			calls++
+			return 0, resp, nil
+		}},
+		settings: settings,
+	}
+
+	stats, err := c.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "1", stats["cluster_size"])
+
+	// Without retries the empty response is returned as is.
+	calls = 0
+	c.settings = defaultOptions()
+	resp, err := c.AsInfoNonEmpty(context.Background(), "statistics")
+	require.NoError(t, err)
+	assert.Empty(t, resp)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithInfoRetriesRejectsNegative(t *testing.T) {
+	settings := defaultOptions()
+
+	require.ErrorIs(t, WithInfoRetries(-1)(&settings), ErrInvalidOption)
+}
+
+func TestAsInfo(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	build, err := container.AsInfo(ctx, "build")
+	require.NoError(t, err)
+	assert.Regexp(t, `^8\.0\.`, build)
+
+	namespaces, err := container.AsInfo(ctx, "namespaces")
+	require.NoError(t, err)
+	assert.Equal(t, "test", namespaces)
+}
+
+func TestVersion(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			switch cmd[len(cmd)-1] {
+			case "build":
+				return 0, "8.0.0.4\n", nil
+			case "edition":
+				return 0, "Aerospike Enterprise Edition\n", nil
+			}
+			return 1, "", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	version, err := c.Version(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, ServerVersion{Major: 8, Minor: 0, Patch: 0, Build: 4, Edition: "Aerospike Enterprise Edition", Raw: "8.0.0.4"}, version)
+	assert.Equal(t, "8.0.0.4", version.String())
+	assert.True(t, version.IsEnterprise())
+	assert.True(t, version.AtLeast(8, 0))
+	assert.True(t, version.AtLeast(7, 2))
+	assert.False(t, version.AtLeast(8, 1))
+}
+
+func TestVersionRejectsUnparsableBuild(t *testing.T) {
+	c := Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			return 0, "unknown", nil
+		}},
+		settings: defaultOptions(),
+	}
+
+	_, err := c.Version(context.Background())
+	require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+}
+
+func TestVersionReportsEdition(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	tests := []struct {
+		name       string
+		opts       []testcontainers.ContainerCustomizer
+		enterprise bool
+	}{
+		{name: "community"},
+		{name: "enterprise", opts: []testcontainers.ContainerCustomizer{WithEnterpriseEdition()}, enterprise: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			container := startContainer(ctx, t, tt.opts...)
+			t.Cleanup(func() {
+				require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+			})
+
+			version, err := container.Version(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 8, version.Major)
+			assert.Equal(t, tt.enterprise, version.IsEnterprise())
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/client"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// ErrUnsupportedDowngrade is returned by Upgrade when the new image is an
+// older server version than the one currently running.
+var ErrUnsupportedDowngrade = errors.New("aerospike does not support downgrading")
+
+// quiesceTimeout bounds how long Cluster.TerminateNode waits for a quiesce to
+// take effect.
+const quiesceTimeout = 30 * time.Second
+
+// ErrNotOnUserNetwork is returned by DisconnectNetwork and ConnectNetwork when
+// the container was not started on a user-defined Docker network.
+var ErrNotOnUserNetwork = errors.New("container is not on a user-defined network")
+
+// Upgrade replaces the running server with one started from image, keeping
+// every other option the container was created with, and waits for it to
+// become ready. It is meant for rolling-upgrade tests that start on one
+// version and move to a newer one.
+//
+// This function performs the following steps:
+// - Rejects the upgrade if image is an older version than the current image
+// - Terminates the current container
+// - Starts a replacement from image with the original options
+//
+// Data only survives the upgrade if it lives on a named volume or bind mount
+// (see testcontainers.WithMounts); anything stored inside the old container
+// is removed with it. Version checks compare the numeric image tags and are
+// skipped when either tag is not a version, such as "latest".
+//
+// The old container is terminated before the replacement starts, so when
+// starting the replacement fails, c no longer refers to a running server and
+// must not be used again; the replacement, if one was created, is terminated
+// too.
+func (c *Container) Upgrade(ctx context.Context, image string) error {
+	if compareImageVersions(c.settings.image, image) > 0 {
+		return fmt.Errorf("%w: %s to %s", ErrUnsupportedDowngrade, c.settings.image, image)
+	}
+
+	opts := append(append([]testcontainers.ContainerCustomizer{}, c.opts...), WithImage(image))
+	req, settings, err := newContainerRequest(opts...)
+	if err != nil {
+		return err
+	}
+
+	// The old container has to go first: a name set with WithContainerName
+	// would otherwise clash with the replacement.
+	if err := c.Terminate(ctx); err != nil {
+		return fmt.Errorf("failed to stop Aerospike before upgrade: %w", err)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		// A replacement that was created but failed to start or become ready
+		// would otherwise be left running.
+		if container != nil {
+			err = errors.Join(err, container.Terminate(ctx))
+		}
+		return fmt.Errorf("failed to start upgraded Aerospike, the container is no longer usable: %w", err)
+	}
+
+	c.Container = container
+	c.settings = settings
+	c.opts = opts
+
+	return nil
+}
+
+// Pause freezes every process in the container with docker pause, so the
+// server stops answering without its connections being closed, for testing
+// how clients handle an unresponsive node: requests time out rather than fail
+// fast. The container keeps its network and port mappings while paused, so
+// Host and the port accessors still return the same addresses, but helpers
+// that run asinfo in the container fail until Unpause is called. Unpause
+// before terminating the container, for example in a deferred cleanup.
+func (c Container) Pause(ctx context.Context) error {
+	return withDockerClient(ctx, func(cli *testcontainers.DockerClient) error {
+		if _, err := cli.ContainerPause(ctx, c.GetContainerID(), client.ContainerPauseOptions{}); err != nil {
+			return fmt.Errorf("failed to pause Aerospike: %w", err)
+		}
+		return nil
+	})
+}
+
+// Unpause resumes a container frozen with Pause. The server picks up where it
+// left off, and clients reconnect or retry as they would after a network
+// stall.
+func (c Container) Unpause(ctx context.Context) error {
+	return withDockerClient(ctx, func(cli *testcontainers.DockerClient) error {
+		if _, err := cli.ContainerUnpause(ctx, c.GetContainerID(), client.ContainerUnpauseOptions{}); err != nil {
+			return fmt.Errorf("failed to unpause Aerospike: %w", err)
+		}
+		return nil
+	})
+}
+
+// Quiesce prepares the node for a clean shutdown: it issues the quiesce info
+// command, so the node gives up its partitions at the next recluster, and
+// asks for that recluster. Only the cluster principal acts on a recluster and
+// the other nodes ignore it, so for a node of a Cluster use
+// Cluster.TerminateNode, which reclusters through every node and waits for
+// the partitions to move before terminating it. Quiesce is an enterprise
+// feature; ErrConfigRejected is returned, with the server's response, when
+// the server refuses it.
+func (c Container) Quiesce(ctx context.Context) error {
+	resp, err := c.AsInfo(ctx, "quiesce:")
+	if err != nil {
+		return err
+	}
+	if resp != "ok" {
+		return fmt.Errorf("%w: quiesce: %s", ErrConfigRejected, resp)
+	}
+
+	return c.recluster(ctx)
+}
+
+// DisconnectNetwork detaches the container from the user-defined Docker
+// networks it was started on, such as the one set with WithNetwork or the
+// network of a Cluster, to simulate a network partition: the node can no
+// longer reach its peers, so in a cluster it splits off on its own, and it
+// can no longer be reached through the ports mapped on that network either.
+// ConnectNetwork reattaches it. ErrNotOnUserNetwork is returned when the
+// container was not started on such a network, as with the default bridge.
+func (c Container) DisconnectNetwork(ctx context.Context) error {
+	networks, _, err := c.userNetworks()
+	if err != nil {
+		return err
+	}
+	connected, err := c.Networks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container networks: %w", err)
+	}
+
+	return withDockerClient(ctx, func(cli *testcontainers.DockerClient) error {
+		for _, name := range networks {
+			if !slices.Contains(connected, name) {
+				continue
+			}
+			if _, err := cli.NetworkDisconnect(ctx, name, client.NetworkDisconnectOptions{Container: c.GetContainerID()}); err != nil {
+				return fmt.Errorf("failed to disconnect Aerospike from network %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ConnectNetwork reattaches a container detached with DisconnectNetwork to its
+// user-defined networks, with the aliases it was started with, so peers can
+// resolve and reach it again. Networks it is still attached to are left alone.
+// A reattached cluster node rejoins the cluster and migrates the partitions
+// written meanwhile; wait for that with WaitForStableCluster.
+func (c Container) ConnectNetwork(ctx context.Context) error {
+	networks, aliases, err := c.userNetworks()
+	if err != nil {
+		return err
+	}
+	connected, err := c.Networks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container networks: %w", err)
+	}
+
+	return withDockerClient(ctx, func(cli *testcontainers.DockerClient) error {
+		for _, name := range networks {
+			if slices.Contains(connected, name) {
+				continue
+			}
+			_, err := cli.NetworkConnect(ctx, name, client.NetworkConnectOptions{
+				Container:      c.GetContainerID(),
+				EndpointConfig: &network.EndpointSettings{Aliases: aliases[name]},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to connect Aerospike to network %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// recluster asks the node to recluster. Nodes other than the cluster
+// principal ignore the request, which is not an error.
+func (c Container) recluster(ctx context.Context) error {
+	resp, err := c.AsInfo(ctx, "recluster:")
+	if err != nil {
+		return err
+	}
+	if resp != "ok" && resp != "ignored-by-non-principal" {
+		return fmt.Errorf("%w: recluster: %s", ErrConfigRejected, resp)
+	}
+
+	return nil
+}
+
+// waitForQuiesced waits until every namespace of a quiesced node reports that
+// the quiesce has taken effect, which happens once the cluster has
+// reclustered.
+func (c Container) waitForQuiesced(ctx context.Context) error {
+	resp, err := c.AsInfo(ctx, "namespaces")
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range strings.Split(resp, ";") {
+		if namespace == "" {
+			continue
+		}
+		err := pollUntil(ctx, quiesceTimeout, func(ctx context.Context) (bool, error) {
+			stats, err := c.NamespaceStats(ctx, namespace)
+			if err != nil {
+				return false, err
+			}
+			return stats["effective_is_quiesced"] == "true", nil
+		})
+		if errors.Is(err, ErrWaitTimeout) {
+			return fmt.Errorf("%w for namespace %q to be quiesced", err, namespace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// userNetworks returns the user-defined networks the container was started
+// on and their aliases, as recorded when it was created.
+func (c Container) userNetworks() ([]string, map[string][]string, error) {
+	var networks []string
+	for _, name := range c.settings.networks {
+		if isUserNetwork(name) {
+			networks = append(networks, name)
+		}
+	}
+	if len(networks) == 0 {
+		return nil, nil, ErrNotOnUserNetwork
+	}
+
+	return networks, c.settings.networkAliases, nil
+}
+
+// isUserNetwork reports whether name is a user-defined Docker network rather
+// than one of the networks Docker provides.
+func isUserNetwork(name string) bool {
+	switch name {
+	case "", "bridge", "host", "none":
+		return false
+	default:
+		return true
+	}
+}
+
+// compareImageVersions compares the version tags of two images, returning a
+// negative number when a is older than b, a positive number when it is newer
+// and 0 when they match or either tag is not a version.
+func compareImageVersions(a, b string) int {
+	va, okA := imageVersion(a)
+	vb, okB := imageVersion(b)
+	if !okA || !okB {
+		return 0
+	}
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+
+	return 0
+}
+
+// imageVersion extracts the dotted numeric version from an image tag, such as
+// [8 0 0 1] from "aerospike/aerospike-server:8.0.0.1" or [7 2] from
+// "aerospike/aerospike-server:7.2_1". It reports false if the tag does not
+// start with a number.
+func imageVersion(image string) ([]int, bool) {
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	if i < 0 {
+		return nil, false
+	}
+	version := parseVersion(name[i+1:])
+
+	return version, len(version) > 0
+}
+
+// parseVersion parses the leading dotted numeric version of s, such as
+// [8 0 0 1] from "8.0.0.1" or [7 2] from "7.2_1". It returns nil if s does not
+// start with a number.
+func parseVersion(s string) []int {
+	if end := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }); end >= 0 {
+		s = s[:end]
+	}
+
+	var version []int
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		version = append(version, n)
+	}
+
+	return version
+}
+
+// withDockerClient calls fn with a Docker client that is closed once fn
+// returns.
+func withDockerClient(ctx context.Context, fn func(cli *testcontainers.DockerClient) error) error {
+	cli, err := testcontainers.NewDockerClientWithOpts(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	return fn(cli)
+}
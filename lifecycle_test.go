@@ -0,0 +1,173 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageVersion(t *testing.T) {
+	tests := []struct {
+		image   string
+		version []int
+		ok      bool
+	}{
+		{image: "aerospike/aerospike-server:8.0", version: []int{8, 0}, ok: true},
+		{image: "aerospike/aerospike-server-enterprise:8.0.0.1", version: []int{8, 0, 0, 1}, ok: true},
+		{image: "aerospike/aerospike-server:7.2_1", version: []int{7, 2}, ok: true},
+		{image: "localhost:5000/aerospike-server:6.4", version: []int{6, 4}, ok: true},
+		{image: "aerospike/aerospike-server:latest"},
+		{image: "localhost:5000/aerospike-server"},
+		{image: "aerospike-server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			version, ok := imageVersion(tt.image)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	assert.Equal(t, []int{8, 0, 0, 1}, parseVersion("8.0.0.1"))
+	assert.Equal(t, []int{7, 2}, parseVersion("7.2_1"))
+	assert.Equal(t, []int{6}, parseVersion("6.x"))
+	assert.Nil(t, parseVersion("latest"))
+}
+
+func TestCompareImageVersions(t *testing.T) {
+	assert.Negative(t, compareImageVersions("aerospike/aerospike-server:7.2", "aerospike/aerospike-server:8.0"))
+	assert.Positive(t, compareImageVersions("aerospike/aerospike-server:8.0.0.1", "aerospike/aerospike-server:8.0"))
+	assert.Zero(t, compareImageVersions("aerospike/aerospike-server:8.0", "aerospike/aerospike-server-enterprise:8.0"))
+	assert.Zero(t, compareImageVersions("aerospike/aerospike-server:latest", "aerospike/aerospike-server:7.2"))
+}
+
+func TestUpgradeRejectsDowngrade(t *testing.T) {
+	// The downgrade check runs before any container is touched.
+	_, settings, err := newContainerRequest(WithImage("aerospike/aerospike-server:8.0"))
+	require.NoError(t, err)
+	c := &Container{settings: settings}
+
+	err = c.Upgrade(context.Background(), "aerospike/aerospike-server:7.2")
+	require.ErrorIs(t, err, ErrUnsupportedDowngrade)
+}
+
+func TestUpgrade(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithImage("aerospike/aerospike-server:7.2"), WithNamespace("upgrade"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	require.NoError(t, container.Upgrade(ctx, communityAerospikeImage))
+
+	build, err := container.AsInfo(ctx, "build")
+	require.NoError(t, err)
+	assert.Regexp(t, `^8\.0\.`, build)
+
+	// The namespace option carries over to the upgraded server.
+	namespaces, err := container.AsInfo(ctx, "namespaces")
+	require.NoError(t, err)
+	assert.Contains(t, namespaces, "upgrade")
+}
+
+func TestPauseTimesOutClients(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "pause", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	require.NoError(t, container.Pause(ctx))
+	t.Cleanup(func() { _ = container.Unpause(ctx) })
+
+	policy := aerospike.NewPolicy()
+	policy.TotalTimeout = 500 * time.Millisecond
+	policy.MaxRetries = 0
+	_, aerr := client.Get(policy, key)
+	require.Error(t, aerr)
+	assert.True(t, aerr.Matches(types.TIMEOUT), "unexpected error: %v", aerr)
+
+	require.NoError(t, container.Unpause(ctx))
+
+	assert.Eventually(t, func() bool {
+		record, aerr := client.Get(policy, key)
+		return aerr == nil && record.Bins["bin"] == "value"
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func TestNetworkHelpersRequireUserNetwork(t *testing.T) {
+	_, bridge, err := newContainerRequest(WithNetwork("bridge"))
+	require.NoError(t, err)
+
+	for _, c := range []Container{{}, {settings: bridge}} {
+		require.ErrorIs(t, c.DisconnectNetwork(context.Background()), ErrNotOnUserNetwork)
+		require.ErrorIs(t, c.ConnectNetwork(context.Background()), ErrNotOnUserNetwork)
+	}
+}
+
+func TestNetworkHelpersUseRecordedNetworks(t *testing.T) {
+	_, settings, err := newContainerRequest(WithNetwork("aerospike-net", "db"))
+	require.NoError(t, err)
+
+	networks, aliases, err := Container{settings: settings}.userNetworks()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aerospike-net"}, networks)
+	assert.Equal(t, map[string][]string{"aerospike-net": {"db"}}, aliases)
+}
+
+func TestQuiesce(t *testing.T) {
+	tests := []struct {
+		name      string
+		quiesce   string
+		recluster string
+		wantErr   error
+	}{
+		{name: "principal", quiesce: "ok", recluster: "ok"},
+		{name: "non-principal", quiesce: "ok", recluster: "ignored-by-non-principal"},
+		{name: "refused", quiesce: "ERROR:4:quiesce is an enterprise feature", wantErr: ErrConfigRejected},
+		{name: "recluster refused", quiesce: "ok", recluster: "ERROR::bad", wantErr: ErrConfigRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commands []string
+			c := Container{
+				Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+					command := cmd[len(cmd)-1]
+					commands = append(commands, command)
+					if command == "quiesce:" {
+						return 0, tt.quiesce, nil
+					}
+					return 0, tt.recluster, nil
+				}},
+				settings: defaultOptions(),
+			}
+
+			err := c.Quiesce(context.Background())
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, []string{"quiesce:", "recluster:"}, commands)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/pkg/stdcopy"
+	"github.com/moby/moby/client"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// DrainLogs returns everything the container has logged so far as a single
+// string, with Docker's stream framing removed, so tests can assert that the
+// server did or did not log a given message.
+//
+// It is named DrainLogs rather than Logs so that Container keeps the Logs
+// method of testcontainers.Container, which streams the same output.
+func (c Container) DrainLogs(ctx context.Context) (string, error) {
+	rc, err := c.Logs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch logs: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	logs, err := io.ReadAll(rc)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	return string(logs), nil
+}
+
+// logsSince returns what the container has logged since since, with Docker's
+// stream framing removed.
+func (c Container) logsSince(ctx context.Context, since time.Time) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := withDockerClient(ctx, func(cli *testcontainers.DockerClient) error {
+		rc, err := cli.ContainerLogs(ctx, c.GetContainerID(), client.ContainerLogsOptions{
+			ShowStdout: true,
+			ShowStderr: true,
+			Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch logs: %w", err)
+		}
+		defer func() { _ = rc.Close() }()
+
+		if _, err := stdcopy.StdCopy(&stdout, &stderr, rc); err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+		return nil
+	})
+
+	return stdout.String() + stderr.String(), err
+}
+
+// WithLogConsumer streams the server's stdout and stderr to consumer while the
+// container runs, which shows why a container failed to start without a
+// manual docker logs. Unlike testcontainers.WithLogConsumers it adds to the
+// consumers already configured, so it can be given several times.
+func WithLogConsumer(consumer testcontainers.LogConsumer) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		if consumer == nil {
+			return fmt.Errorf("%w: log consumer is nil", ErrInvalidOption)
+		}
+		if req.LogConsumerCfg == nil {
+			req.LogConsumerCfg = &testcontainers.LogConsumerConfig{}
+		}
+		req.LogConsumerCfg.Consumers = append(req.LogConsumerCfg.Consumers, consumer)
+
+		return nil
+	}
+}
+
+// WithLogToTestWriter streams the server's output to t.Logf, one call per
+// line, so it shows up next to the failing test with go test -v or on
+// failure. The container must be terminated before t completes, for example
+// from t.Cleanup, as testing panics on logs written after that.
+func WithLogToTestWriter(t testing.TB) testcontainers.CustomizeRequestOption {
+	return WithLogConsumer(testLogConsumer{t: t})
+}
+
+// testLogConsumer writes each log line to a test's log.
+type testLogConsumer struct {
+	t testing.TB
+}
+
+// Accept logs l without its trailing newline.
+func (c testLogConsumer) Accept(l testcontainers.Log) {
+	c.t.Logf("aerospike: %s", strings.TrimRight(string(l.Content), "\r\n"))
+}
@@ -0,0 +1,67 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// collectingConsumer records every log line it receives.
+type collectingConsumer struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *collectingConsumer) Accept(l testcontainers.Log) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, string(l.Content))
+}
+
+func (c *collectingConsumer) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+func TestWithLogConsumerAppends(t *testing.T) {
+	first, second := &collectingConsumer{}, &collectingConsumer{}
+
+	req, _, err := newContainerRequest(WithLogConsumer(first), WithLogConsumer(second), WithLogToTestWriter(t))
+	require.NoError(t, err)
+
+	require.NotNil(t, req.LogConsumerCfg)
+	require.Len(t, req.LogConsumerCfg.Consumers, 3)
+	assert.Same(t, first, req.LogConsumerCfg.Consumers[0])
+	assert.Same(t, second, req.LogConsumerCfg.Consumers[1])
+
+	_, _, err = newContainerRequest(WithLogConsumer(nil))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithLogConsumer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+	consumer := &collectingConsumer{}
+
+	container := startContainer(ctx, t, WithLogConsumer(consumer), WithLogToTestWriter(t))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	// The server logs that it is ready before the wait strategy succeeds.
+	assert.Eventually(t, func() bool {
+		for _, line := range consumer.Lines() {
+			if strings.Contains(line, "service ready") {
+				return true
+			}
+		}
+		return false
+	}, defaultStartupTimeout, defaultPollInterval)
+}
@@ -0,0 +1,181 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	// exporterImage is the exporter image started by WithPrometheusExporter.
+	// It is pinned so scrapes see the same metric names on every run; use
+	// WithPrometheusExporterImage for another release.
+	exporterImage = "aerospike/aerospike-prometheus-exporter:v1.16.1"
+	exporterPort  = "9145/tcp"
+	// exporterStartupTimeout bounds how long the exporter may take to serve
+	// its first scrape.
+	exporterStartupTimeout = 60 * time.Second
+)
+
+// ErrMetricsNotEnabled is returned by MetricsPort when the container was not
+// started with WithPrometheusExporter.
+var ErrMetricsNotEnabled = errors.New("prometheus exporter not enabled")
+
+// metricsExporter tracks the exporter sidecar started for a container. It is
+// shared by every copy of the options, so the hooks that start and stop the
+// sidecar and the Container that reports its port see the same one.
+type metricsExporter struct {
+	image     string
+	container testcontainers.Container
+}
+
+// WithPrometheusExporter starts the Aerospike Prometheus exporter next to the
+// server once it is ready, so scrape configurations and dashboards can be
+// tested against real metrics. The server images do not bundle the exporter,
+// so it runs as a sidecar container from the
+// aerospike/aerospike-prometheus-exporter image, which is pulled on first use.
+// It authenticates with the credentials set with WithSecurity, joins the
+// user-defined network of the server, if any, and is terminated along with the
+// server. MetricsPort returns its mapped port.
+func WithPrometheusExporter() Option {
+	return WithPrometheusExporterImage(exporterImage)
+}
+
+// WithPrometheusExporterImage is like WithPrometheusExporter but runs the
+// exporter from image, for testing against another exporter release or
+// pulling it from a mirror.
+func WithPrometheusExporterImage(image string) Option {
+	return func(o *options) error {
+		if image = strings.TrimSpace(image); image == "" {
+			return fmt.Errorf("%w: exporter image is empty", ErrInvalidOption)
+		}
+		o.exporter = &metricsExporter{image: image}
+		return nil
+	}
+}
+
+// MetricsPort returns the mapped port at which the exporter started with
+// WithPrometheusExporter serves metrics at /metrics, on the host returned by
+// Host.
+func (c Container) MetricsPort(ctx context.Context) (int, error) {
+	if c.settings.exporter == nil || c.settings.exporter.container == nil {
+		return 0, ErrMetricsNotEnabled
+	}
+
+	port, err := c.settings.exporter.container.MappedPort(ctx, exporterPort)
+	if err != nil {
+		return 0, err
+	}
+	return int(port.Num()), nil
+}
+
+// attach adds the hooks that start the exporter once the server is ready and
+// terminate it before the server.
+func (e *metricsExporter) attach(req *testcontainers.GenericContainerRequest, settings options) {
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostReadies: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				return e.start(ctx, c, settings)
+			},
+		},
+		PreTerminates: []testcontainers.ContainerHook{
+			func(ctx context.Context, _ testcontainers.Container) error {
+				return e.terminate(ctx)
+			},
+		},
+	})
+}
+
+// start runs the exporter against the server in c and waits until it serves
+// metrics.
+func (e *metricsExporter) start(ctx context.Context, c testcontainers.Container, settings options) error {
+	req, err := e.request(ctx, c, settings)
+	if err != nil {
+		return err
+	}
+
+	exporter, err := testcontainers.GenericContainer(ctx, req)
+	if exporter != nil {
+		e.container = exporter
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start the Prometheus exporter from %s; it must be pullable, or drop WithPrometheusExporter: %w", e.image, err)
+	}
+
+	return nil
+}
+
+// request assembles the exporter request for the server in c. The server's
+// address on the default bridge network is not reachable from a user-defined
+// network, so when the server is on one the exporter joins it and reaches the
+// server by its alias, or by its container name when it has none.
+func (e *metricsExporter) request(ctx context.Context, c testcontainers.Container, settings options) (testcontainers.GenericContainerRequest, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        e.image,
+			ExposedPorts: []string{exporterPort},
+			WaitingFor: wait.ForHTTP("/metrics").
+				WithPort(exporterPort).
+				WithStartupTimeout(exporterStartupTimeout),
+		},
+		Started: true,
+	}
+
+	networks, err := c.Networks(ctx)
+	if err != nil {
+		return req, fmt.Errorf("failed to fetch server networks for the Prometheus exporter: %w", err)
+	}
+	var host string
+	for _, name := range networks {
+		if !isUserNetwork(name) {
+			continue
+		}
+		var aliases map[string][]string
+		if aliases, err = c.NetworkAliases(ctx); err != nil {
+			return req, fmt.Errorf("failed to fetch server aliases for the Prometheus exporter: %w", err)
+		}
+		if len(aliases[name]) > 0 {
+			host = aliases[name][0]
+		} else if host, err = c.Name(ctx); err != nil {
+			return req, fmt.Errorf("failed to fetch server name for the Prometheus exporter: %w", err)
+		}
+		host = strings.TrimPrefix(host, "/")
+		req.Networks = []string{name}
+		break
+	}
+	if host == "" {
+		if host, err = c.ContainerIP(ctx); err != nil {
+			return req, fmt.Errorf("failed to fetch server address for the Prometheus exporter: %w", err)
+		}
+	}
+
+	req.Env = map[string]string{
+		"AS_HOST": host,
+		"AS_PORT": strconv.Itoa(settings.serviceContainerPort()),
+	}
+	if settings.user != "" {
+		req.Env["AS_AUTH_USER"] = settings.user
+		req.Env["AS_AUTH_PASSWORD"] = settings.password
+	}
+
+	return req, nil
+}
+
+// terminate stops the exporter, if it was started.
+func (e *metricsExporter) terminate(ctx context.Context) error {
+	if e.container == nil {
+		return nil
+	}
+	if err := e.container.Terminate(ctx); err != nil {
+		return fmt.Errorf("failed to terminate the Prometheus exporter: %w", err)
+	}
+	e.container = nil
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package aerospike
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPrometheusExporterAddsHooks(t *testing.T) {
+	plain, _, err := newContainerRequest()
+	require.NoError(t, err)
+
+	req, settings, err := newContainerRequest(WithPrometheusExporter())
+	require.NoError(t, err)
+
+	require.NotNil(t, settings.exporter)
+	require.Len(t, req.LifecycleHooks, len(plain.LifecycleHooks)+1)
+	hooks := req.LifecycleHooks[len(req.LifecycleHooks)-1]
+	assert.Len(t, hooks.PostReadies, 1)
+	assert.Len(t, hooks.PreTerminates, 1)
+}
+
+func TestPrometheusExporterRequest(t *testing.T) {
+	_, settings, err := newContainerRequest(WithPrometheusExporter(), WithServicePort(4000))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		server      *fakeContainer
+		wantHost    string
+		wantNetwork []string
+	}{
+		{name: "default bridge", server: &fakeContainer{ip: "172.17.0.2"}, wantHost: "172.17.0.2"},
+		{
+			name:        "user network with alias",
+			server:      &fakeContainer{networks: map[string][]string{"aerospike-net": {"db"}}},
+			wantHost:    "db",
+			wantNetwork: []string{"aerospike-net"},
+		},
+		{
+			name:        "user network without alias",
+			server:      &fakeContainer{networks: map[string][]string{"aerospike-net": nil}},
+			wantHost:    "aerospike-node",
+			wantNetwork: []string{"aerospike-net"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := settings.exporter.request(context.Background(), tt.server, settings)
+			require.NoError(t, err)
+
+			assert.Equal(t, exporterImage, req.Image)
+			assert.Equal(t, tt.wantNetwork, req.Networks)
+			assert.Equal(t, tt.wantHost, req.Env["AS_HOST"])
+			assert.Equal(t, "4000", req.Env["AS_PORT"])
+		})
+	}
+}
+
+func TestWithPrometheusExporterImage(t *testing.T) {
+	_, settings, err := newContainerRequest(WithPrometheusExporterImage("mirror.local/aerospike-prometheus-exporter:v1.15.0"))
+	require.NoError(t, err)
+	require.NotNil(t, settings.exporter)
+	assert.Equal(t, "mirror.local/aerospike-prometheus-exporter:v1.15.0", settings.exporter.image)
+
+	_, _, err = newContainerRequest(WithPrometheusExporterImage(" "))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestMetricsPortNotEnabled(t *testing.T) {
+	c := Container{settings: defaultOptions()}
+
+	_, err := c.MetricsPort(context.Background())
+	require.ErrorIs(t, err, ErrMetricsNotEnabled)
+
+	require.NoError(t, WithPrometheusExporter()(&c.settings))
+	_, err = c.MetricsPort(context.Background())
+	require.ErrorIs(t, err, ErrMetricsNotEnabled)
+}
+
+func TestWithPrometheusExporterServesMetrics(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithPrometheusExporter())
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MetricsPort(ctx)
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+":"+strconv.Itoa(port)+"/metrics", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "aerospike_")
+}
@@ -0,0 +1,764 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// StorageEngine selects where a namespace keeps its data.
+type StorageEngine string
+
+const (
+	// StorageMemory keeps the namespace in memory only.
+	StorageMemory StorageEngine = "memory"
+	// StorageDevice keeps the namespace in a file inside the container.
+	StorageDevice StorageEngine = "device"
+)
+
+// minTmpfsDataDirSize is the smallest tmpfs WithTmpfsDataDir accepts.
+const minTmpfsDataDirSize = 64 << 20
+
+// maxRecordSizeLimit is the largest max-record-size the server accepts.
+const maxRecordSizeLimit = 8 << 20
+
+// NamespaceConfig fully describes one namespace for WithNamespaceConfig. Zero
+// values select the same defaults as the namespace the package creates on its
+// own.
+type NamespaceConfig struct {
+	// Name is the namespace name. It is required.
+	Name string
+	// StorageEngine defaults to StorageMemory. StorageDevice stores the data in
+	// a file under /opt/aerospike/data.
+	StorageEngine StorageEngine
+	// ReplicationFactor defaults to 1.
+	ReplicationFactor int
+	// DefaultTTL is applied to records written without a TTL. The default of
+	// zero means records never expire. A non-zero DefaultTTL requires
+	// NsupPeriod, as the server refuses to start otherwise.
+	DefaultTTL time.Duration
+	// NsupPeriod is how often expired and evicted records are removed. The
+	// default of zero disables the namespace supervisor.
+	NsupPeriod time.Duration
+	// SizeGiB is the size of the storage in gibibytes: the data size for
+	// StorageMemory or the file size for StorageDevice. It defaults to 1.
+	SizeGiB int
+	// EvictUsedPct starts evicting records once this percentage of the storage
+	// is used. Zero disables the threshold.
+	EvictUsedPct int
+	// EvictSysMemoryPct starts evicting records once this percentage of the
+	// system memory is used. Zero disables the threshold.
+	EvictSysMemoryPct int
+}
+
+// WithNamespaceConfig defines a namespace from cfg in one step, replacing
+// anything earlier options set for a namespace of the same name. Call it
+// several times to define several namespaces; the default namespace is kept
+// alongside them. cfg is validated as a whole when the option is applied.
+//
+// Namespaces are static configuration, so this renders a server configuration
+// file in place of the image defaults.
+func WithNamespaceConfig(cfg NamespaceConfig) Option {
+	return func(o *options) error {
+		name, err := normalizeNamespace(cfg.Name, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		cfg.Name = name
+		if err := cfg.validate(); err != nil {
+			return fmt.Errorf("namespace %q: %w", name, err)
+		}
+
+		o.configEdits = append(o.configEdits, func(sc *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.render(sc)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithNamespaces creates each of names at startup with the same defaults as
+// the default namespace: in memory, replication factor 1 and no expiration.
+// The namespace set with WithNamespace, or "test" without it, is always
+// created as well, so passing it again here is harmless. Use
+// WithNamespaceConfig to define a namespace with other settings.
+func WithNamespaces(names ...string) Option {
+	return func(o *options) error {
+		if len(names) == 0 {
+			return fmt.Errorf("%w: no namespaces given", ErrInvalidOption)
+		}
+		namespaces := make([]string, 0, len(names))
+		for _, name := range names {
+			namespace, err := normalizeNamespace(name, ErrInvalidOption)
+			if err != nil {
+				return err
+			}
+			namespaces = append(namespaces, namespace)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			for _, namespace := range namespaces {
+				cfg.namespace(namespace)
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithMemorySize sets the size of namespace's in-memory storage, such as "2G",
+// so write-heavy tests do not hit stop-writes at the 1G default. size is a
+// number of bytes with an optional K, M, G or T suffix.
+//
+// The rendered configuration uses the storage syntax of server 7.0 and later,
+// where the size is the data-size of the memory storage engine. It is static
+// there and can only change with a restart. Servers before 7.0 configured it
+// with memory-size, which could also be changed at runtime with set-config;
+// the configuration file rendered by this package does not support them.
+//
+// The namespace must use the memory storage engine.
+func WithMemorySize(namespace, size string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		size, err := normalizeConfigSize(size)
+		if err != nil {
+			return err
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			for _, c := range cfg.namespace(namespace).children {
+				if strings.HasPrefix(c.name, "storage-engine ") && c.name != "storage-engine memory" {
+					return fmt.Errorf("%w: namespace %q uses %s, memory size only applies to storage-engine memory", ErrInvalidOption, namespace, c.name)
+				}
+			}
+			engine := cfg.storageEngine(namespace, "memory")
+			if engine.value("file") != "" {
+				// The persistence file size determines the namespace size.
+				return fmt.Errorf("%w: namespace %q is sized by its persistence file", ErrInvalidOption, namespace)
+			}
+			engine.set("data-size", size)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithStorageEngineDevice stores namespace in a file inside the container,
+// /opt/aerospike/data/<namespace>.dat, of the given fileSize, such as "4G".
+// Reads and writes go through the device code path, so persistence, defrag
+// and write-block behavior can be tested without a raw device. The data is
+// lost when the container is terminated.
+func WithStorageEngineDevice(namespace, fileSize string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		fileSize, err := normalizeConfigSize(fileSize)
+		if err != nil {
+			return err
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			engine := cfg.storageEngine(namespace, "device")
+			engine.params = nil
+			engine.set("file", dataDir+"/"+namespace+".dat")
+			engine.set("filesize", fileSize)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithPersistentVolume mounts the host directory hostPath at the server's data
+// directory, /opt/aerospike/data, and stores the default namespace (see
+// WithNamespace) on a device file there, unless an earlier option already put
+// it on a device. The records then live on the host: they survive stopping
+// and starting the container, and a new container started with the same
+// hostPath picks them up, which is what recovery tests need. The file is
+// created with a size of 1G.
+//
+// hostPath is a bind mount, not a Docker volume, so neither Terminate nor the
+// Testcontainers reaper removes it or its contents; the caller owns the
+// directory and its cleanup. t.TempDir is a good fit in tests, as it is
+// removed only after the cleanups registered later, such as the one that
+// terminates the container. The server writes the file as the user it runs as
+// in the image, so the directory must be writable by it.
+func WithPersistentVolume(hostPath string) Option {
+	return func(o *options) error {
+		if strings.TrimSpace(hostPath) == "" {
+			return fmt.Errorf("%w: persistent volume path is empty", ErrInvalidOption)
+		}
+		path, err := filepath.Abs(hostPath)
+		if err != nil {
+			return fmt.Errorf("%w: persistent volume path %q: %w", ErrInvalidOption, hostPath, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%w: persistent volume: %w", ErrInvalidOption, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%w: persistent volume %q is not a directory", ErrInvalidOption, path)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			storeDefaultNamespaceOnDevice(cfg, req, "1G")
+			addHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+				hostConfig.Binds = append(hostConfig.Binds, path+":"+dataDir)
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithTmpfsDataDir mounts a tmpfs of sizeBytes bytes at the server's data
+// directory, /opt/aerospike/data, and stores the default namespace (see
+// WithNamespace) on a device file there that fills it, unless an earlier
+// option already put it on a device. Records then go through the device code
+// path at memory speed and without touching the host's disk, which suits CI.
+// Device files set with other options must fit in the tmpfs, and the data is
+// lost when the container stops. It cannot be combined with
+// WithPersistentVolume, which mounts the same directory.
+//
+// sizeBytes is rounded down to whole mebibytes and must be at least 64 MiB,
+// so the device has room for the write blocks the server keeps in use.
+func WithTmpfsDataDir(sizeBytes int64) Option {
+	return func(o *options) error {
+		if sizeBytes < minTmpfsDataDirSize {
+			return fmt.Errorf("%w: tmpfs data directory must be at least %d bytes, got %d", ErrInvalidOption, minTmpfsDataDirSize, sizeBytes)
+		}
+		mebibytes := strconv.FormatInt(sizeBytes>>20, 10)
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			storeDefaultNamespaceOnDevice(cfg, req, mebibytes+"M")
+			addHostConfigModifier(req, func(hostConfig *container.HostConfig) {
+				if hostConfig.Tmpfs == nil {
+					hostConfig.Tmpfs = make(map[string]string)
+				}
+				hostConfig.Tmpfs[dataDir] = "rw,size=" + mebibytes + "m"
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithDataInMemory keeps a copy of the data of a device-backed namespace in
+// memory when enabled, so reads are served from memory while writes are still
+// persisted to the namespace's file.
+//
+// Servers before 7.0 configured this with data-in-memory on the device storage
+// engine. From 7.0 that parameter is gone and the same layout is a memory
+// storage engine backed by a file, which is what the rendered configuration
+// uses: enabling it turns the device engine into a file-backed memory engine,
+// and disabling it turns a file-backed memory engine back into a device
+// engine, keeping the file and its size. It returns ErrInvalidOption at
+// startup for a namespace held in memory only, as there is no device to
+// persist to; combine it with WithStorageEngineDevice or NamespaceConfig.
+func WithDataInMemory(namespace string, enabled bool) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			var current *stanza
+			for _, c := range cfg.namespace(namespace).children {
+				if strings.HasPrefix(c.name, "storage-engine ") {
+					current = c
+				}
+			}
+			if current == nil || current.value("file") == "" {
+				return fmt.Errorf("%w: data in memory only applies to namespace %q when it is stored on a device", ErrInvalidOption, namespace)
+			}
+
+			kind := "device"
+			if enabled {
+				kind = "memory"
+			}
+			params := current.params
+			cfg.storageEngine(namespace, kind).params = params
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithReplicationFactor sets how many copies of each record namespace keeps
+// across the cluster. A single node always holds one copy, whatever the
+// factor, so this matters for multi-node clusters.
+func WithReplicationFactor(namespace string, rf int) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		if rf < 1 {
+			return fmt.Errorf("%w: replication factor must be at least 1, got %d", ErrInvalidOption, rf)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.namespace(namespace).set("replication-factor", strconv.Itoa(rf))
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithDefaultTTL sets the default-ttl of namespace, the time to live in seconds
+// of records written without an explicit TTL. Zero means such records never
+// expire, which is the default.
+//
+// Records only expire while the namespace supervisor runs, so combine a
+// non-zero TTL with WithTTLSupport or WithNsupPeriod; a short period lets
+// records disappear on their own within a test.
+func WithDefaultTTL(namespace string, seconds int) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		if seconds < 0 {
+			return fmt.Errorf("%w: default TTL must not be negative, got %d", ErrInvalidOption, seconds)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			ns := cfg.namespace(namespace)
+			ns.set("default-ttl", strconv.Itoa(seconds))
+			// The server refuses a default TTL while nsup-period is 0, which
+			// WithNsupPeriod only changes once the server is running.
+			if seconds > 0 && ns.value("nsup-period") == "0" {
+				ns.set("allow-ttl-without-nsup", "true")
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithRackID assigns the node to rack rackID for namespace. Replicas of a
+// partition are spread across racks and rack-aware clients prefer reads from
+// their own rack, so give each node of a cluster its own rack with
+// WithClusterNode. Rack 0 is the default and means no rack.
+func WithRackID(namespace string, rackID int) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		if rackID < 0 {
+			return fmt.Errorf("%w: rack ID must not be negative, got %d", ErrInvalidOption, rackID)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.namespace(namespace).set("rack-id", strconv.Itoa(rackID))
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithCompression compresses the records namespace stores with algorithm,
+// one of "lz4", "snappy" or "zstd", by setting compression on the namespace's
+// storage engine. Use CompressionRatio to check how well the stored records
+// compress. Options that replace the storage engine, such as
+// WithStorageEngineDevice, must come before this one.
+//
+// Compression is an enterprise feature: it requires WithEnterpriseEdition and,
+// outside the single-node evaluation mode, a feature key that enables it (see
+// WithFeatureKeyFile).
+func WithCompression(namespace, algorithm string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		switch algorithm {
+		case "lz4", "snappy", "zstd":
+		default:
+			return fmt.Errorf("%w: unknown compression algorithm %q, want lz4, snappy or zstd", ErrInvalidOption, algorithm)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				return fmt.Errorf("%w: compression requires an enterprise image, got %q", ErrInvalidOption, req.Image)
+			}
+			for _, c := range cfg.namespace(namespace).children {
+				if strings.HasPrefix(c.name, "storage-engine ") {
+					c.set("compression", algorithm)
+				}
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithStopWritesPct makes the server reject writes to namespace with an out
+// of space error (SERVER_MEM_ERROR in the client) once pct percent of its
+// storage is used, by setting stop-writes-used-pct on its storage engine.
+// Startup fails with ErrConfigRejected when the server does not report the
+// value once it is ready.
+// Combined with a small WithMemorySize, it drives the namespace into
+// stop-writes after a few writes, for testing how an application handles the
+// rejection. The server re-evaluates the threshold periodically, so a few
+// writes past it may still succeed. 0 disables the threshold.
+//
+// The rendered configuration uses the server 7.0 parameter. Servers before
+// 7.0 used stop-writes-pct on the namespace instead, which the configuration
+// file rendered by this package does not support. Options that replace the
+// storage engine, such as WithStorageEngineDevice, must come before this one.
+func WithStopWritesPct(namespace string, pct int) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("%w: stop-writes percentage must be between 0 and 100, got %d", ErrInvalidOption, pct)
+		}
+
+		value := strconv.Itoa(pct)
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			for _, c := range cfg.namespace(namespace).children {
+				if strings.HasPrefix(c.name, "storage-engine ") {
+					c.set("stop-writes-used-pct", value)
+				}
+			}
+
+			req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						// o holds every option by the time the hook runs.
+						config, err := Container{Container: c, settings: *o}.getConfig(ctx, "namespace", namespace)
+						if err != nil {
+							return err
+						}
+						if got := config["storage-engine.stop-writes-used-pct"]; got != value {
+							return fmt.Errorf("%w: stop-writes-used-pct of namespace %q is %q after setting it to %q", ErrConfigRejected, namespace, got, value)
+						}
+						return nil
+					},
+				},
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithMaxRecordSize sets the largest record namespace accepts, max-record-size,
+// such as "4M", so tests can write records near the limit or check how an
+// application handles RECORD_TOO_BIG. size is a number of bytes with an
+// optional K or M suffix and may not exceed 8M, the size of the server's
+// write blocks. The server default is 1M.
+//
+// Servers before 7.1 had no max-record-size and limited records through the
+// write-block-size of the storage engine instead; use WithWriteBlockSize,
+// which picks the right setting for the image, to test those.
+func WithMaxRecordSize(namespace, size string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		size, err := normalizeConfigSize(size)
+		if err != nil {
+			return err
+		}
+		if n := configSizeBytes(size); n < 0 || n > maxRecordSizeLimit {
+			return fmt.Errorf("%w: max record size %s exceeds the 8M write block size", ErrInvalidOption, size)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.namespace(namespace).set("max-record-size", size)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithWriteBlockSize limits the records namespace accepts to size, a power of
+// two between 1K and 8M such as "1M", for testing large records near the
+// block-size limit. On server 7.1 and later, and for image tags that are not a
+// version, it sets max-record-size like WithMaxRecordSize. Older servers
+// limit records through the write-block-size of the storage engine, which it
+// sets instead. Before 7.0 only device storage engines have a write block
+// size, so for those images it returns ErrInvalidOption at startup unless
+// namespace is stored on a device; options that change its storage must come
+// before this one.
+func WithWriteBlockSize(namespace, size string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		size, err := normalizeConfigSize(size)
+		if err != nil {
+			return err
+		}
+		if n := configSizeBytes(size); n < 1<<10 || n > maxRecordSizeLimit || n&(n-1) != 0 {
+			return fmt.Errorf("%w: write block size %s must be a power of two between 1K and 8M", ErrInvalidOption, size)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			ns := cfg.namespace(namespace)
+			version, ok := imageVersion(req.Image)
+			if !ok || slices.Compare(version, []int{7, 1}) >= 0 {
+				ns.set("max-record-size", size)
+				return nil
+			}
+
+			var engine *stanza
+			for _, c := range ns.children {
+				if strings.HasPrefix(c.name, "storage-engine ") {
+					engine = c
+				}
+			}
+			if engine == nil || (version[0] < 7 && engine.name != "storage-engine device") {
+				return fmt.Errorf("%w: servers before 7.0 only have a write block size on device storage, store namespace %q on a device", ErrInvalidOption, namespace)
+			}
+			engine.set("write-block-size", size)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithSingleBin makes namespace a single-bin namespace, for reproducing the
+// behavior of legacy deployments: each record holds one bin, whose name is
+// ignored, so writes with more than one bin are rejected. Seed reports such
+// records as failed in its *SeedError, and operations that need bin names,
+// such as secondary indexes on a named bin, do not apply.
+//
+// Single-bin namespaces were removed in server 7.0, so this requires an older
+// image, such as aerospike/aerospike-server:6.4 set with WithImage, and
+// returns ErrInvalidOption at startup for any other image, including tags that
+// are not a version. Those servers size memory namespaces differently, so the
+// rendered configuration switches every memory namespace, not just namespace,
+// to their syntax; a namespace kept in a memory file, which they lack, is
+// rejected. namespace must be held in memory, and options that change the
+// storage of any namespace must come before this one.
+func WithSingleBin(namespace string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if version, ok := imageVersion(req.Image); !ok || version[0] >= 7 {
+				return fmt.Errorf("%w: single-bin was removed in server 7.0, use an older image such as aerospike/aerospike-server:6.4, got %q", ErrInvalidOption, req.Image)
+			}
+
+			ns := cfg.namespace(namespace)
+			if !useLegacyMemoryEngine(ns) {
+				return fmt.Errorf("%w: single-bin requires namespace %q to be held in memory", ErrInvalidOption, namespace)
+			}
+			ns.set("single-bin", "true")
+
+			for _, name := range cfg.namespaces() {
+				other := cfg.namespace(name)
+				if slices.ContainsFunc(other.children, isMemoryEngine) && !useLegacyMemoryEngine(other) {
+					return fmt.Errorf("%w: namespace %q keeps its data in a memory file, which servers before 7.0 do not support", ErrInvalidOption, name)
+				}
+			}
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// validate checks cfg for values the server would reject.
+func (cfg NamespaceConfig) validate() error {
+	switch cfg.StorageEngine {
+	case "", StorageMemory, StorageDevice:
+	default:
+		return fmt.Errorf("%w: unknown storage engine %q", ErrInvalidOption, cfg.StorageEngine)
+	}
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"replication factor", cfg.ReplicationFactor},
+		{"size", cfg.SizeGiB},
+	}
+	for _, c := range counts {
+		if c.value < 0 {
+			return fmt.Errorf("%w: %s must not be negative, got %d", ErrInvalidOption, c.name, c.value)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"default TTL", cfg.DefaultTTL},
+		{"nsup period", cfg.NsupPeriod},
+	}
+	for _, d := range durations {
+		if d.value < 0 || d.value%time.Second != 0 {
+			return fmt.Errorf("%w: %s must be a non-negative whole number of seconds, got %s", ErrInvalidOption, d.name, d.value)
+		}
+	}
+	if cfg.DefaultTTL > 0 && cfg.NsupPeriod == 0 {
+		return fmt.Errorf("%w: default TTL requires a non-zero nsup period", ErrInvalidOption)
+	}
+
+	percentages := []struct {
+		name  string
+		value int
+	}{
+		{"evict-used-pct", cfg.EvictUsedPct},
+		{"evict-sys-memory-pct", cfg.EvictSysMemoryPct},
+	}
+	for _, p := range percentages {
+		if p.value < 0 || p.value > 100 {
+			return fmt.Errorf("%w: %s must be between 0 and 100, got %d", ErrInvalidOption, p.name, p.value)
+		}
+	}
+
+	return nil
+}
+
+// render replaces the namespace stanza for cfg in sc.
+func (cfg NamespaceConfig) render(sc *serverConfig) {
+	ns := sc.root.child("namespace " + cfg.Name)
+	ns.params = nil
+	ns.children = nil
+
+	replicationFactor := max(cfg.ReplicationFactor, 1)
+	ns.set("replication-factor", strconv.Itoa(replicationFactor))
+	ns.set("default-ttl", strconv.Itoa(int(cfg.DefaultTTL/time.Second)))
+	ns.set("nsup-period", strconv.Itoa(int(cfg.NsupPeriod/time.Second)))
+	if cfg.EvictSysMemoryPct > 0 {
+		ns.set("evict-sys-memory-pct", strconv.Itoa(cfg.EvictSysMemoryPct))
+	}
+
+	size := strconv.Itoa(max(cfg.SizeGiB, 1)) + "G"
+	var engine *stanza
+	if cfg.StorageEngine == StorageDevice {
+		engine = ns.child("storage-engine device")
+		engine.set("file", dataDir+"/"+cfg.Name+".dat")
+		engine.set("filesize", size)
+	} else {
+		engine = ns.child("storage-engine memory")
+		engine.set("data-size", size)
+	}
+	if cfg.EvictUsedPct > 0 {
+		engine.set("evict-used-pct", strconv.Itoa(cfg.EvictUsedPct))
+	}
+}
+
+// configSizePattern matches the sizes accepted in aerospike.conf.
+var configSizePattern = regexp.MustCompile(`^[0-9]+[KMGT]?$`)
+
+// configSizeBytes returns the number of bytes of a size normalized by
+// normalizeConfigSize, or -1 if it overflows.
+func configSizeBytes(size string) int64 {
+	shift := 0
+	if i := strings.IndexAny(size, "KMGT"); i >= 0 {
+		shift = 10 * (strings.IndexByte("KMGT", size[i]) + 1)
+		size = size[:i]
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || n > math.MaxInt64>>shift {
+		return -1
+	}
+
+	return n << shift
+}
+
+// storeDefaultNamespaceOnDevice stores the default namespace on a device file
+// of fileSize in the data directory, unless it is already on a device.
+func storeDefaultNamespaceOnDevice(cfg *serverConfig, req *testcontainers.GenericContainerRequest, fileSize string) {
+	namespace := defaultNamespace
+	if ns := req.Env["NAMESPACE"]; ns != "" {
+		namespace = ns
+	}
+	for _, c := range cfg.namespace(namespace).children {
+		if c.name == "storage-engine device" {
+			return
+		}
+	}
+
+	engine := cfg.storageEngine(namespace, "device")
+	engine.params = nil
+	engine.set("file", dataDir+"/"+namespace+".dat")
+	engine.set("filesize", fileSize)
+}
+
+// normalizeConfigSize validates a size such as "512M" or "2G" and returns it in
+// the form aerospike.conf expects.
+func normalizeConfigSize(size string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(size))
+	if !configSizePattern.MatchString(normalized) || strings.TrimLeft(strings.TrimRight(normalized, "KMGT"), "0") == "" {
+		return "", fmt.Errorf("%w: invalid size %q, expected a positive number with an optional K, M, G or T suffix", ErrInvalidOption, size)
+	}
+
+	return normalized, nil
+}
+
+// isMemoryEngine reports whether c is a memory storage-engine stanza.
+func isMemoryEngine(c *stanza) bool {
+	return c.name == "storage-engine memory"
+}
+
+// useLegacyMemoryEngine switches ns from the memory storage engine of server
+// 7.0 and later to the syntax of older servers, where the namespace is sized
+// with memory-size and the engine takes no parameters. It reports false,
+// leaving ns alone, when ns is not held purely in memory.
+func useLegacyMemoryEngine(ns *stanza) bool {
+	if ns.value("storage-engine") == "memory" {
+		return true
+	}
+
+	var engine *stanza
+	for _, c := range ns.children {
+		if strings.HasPrefix(c.name, "storage-engine ") {
+			engine = c
+		}
+	}
+	if engine == nil || !isMemoryEngine(engine) || engine.value("file") != "" {
+		return false
+	}
+
+	if size := engine.value("data-size"); size != "" {
+		ns.set("memory-size", size)
+	}
+	ns.removeChildren("storage-engine")
+	ns.set("storage-engine", "memory")
+	return true
+}
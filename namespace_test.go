@@ -0,0 +1,702 @@
+package aerospike
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithNamespaceConfigDefaults(t *testing.T) {
+	conf := renderedConfig(t, WithNamespaceConfig(NamespaceConfig{Name: " users "}))
+
+	assert.Contains(t, conf, "namespace users {\n"+
+		"\treplication-factor 1\n"+
+		"\tdefault-ttl 0\n"+
+		"\tnsup-period 0\n"+
+		"\tstorage-engine memory {\n"+
+		"\t\tdata-size 1G\n"+
+		"\t}\n"+
+		"}\n")
+	// The default namespace is kept.
+	assert.Contains(t, conf, "namespace test {\n")
+}
+
+func TestWithNamespaceConfig(t *testing.T) {
+	conf := renderedConfig(t,
+		WithNamespaceConfig(NamespaceConfig{
+			Name:              "users",
+			StorageEngine:     StorageDevice,
+			ReplicationFactor: 2,
+			DefaultTTL:        time.Hour,
+			NsupPeriod:        10 * time.Second,
+			SizeGiB:           4,
+			EvictUsedPct:      60,
+			EvictSysMemoryPct: 80,
+		}),
+		WithNamespaceConfig(NamespaceConfig{Name: "cache", SizeGiB: 2}),
+	)
+
+	assert.Contains(t, conf, "namespace users {\n"+
+		"\treplication-factor 2\n"+
+		"\tdefault-ttl 3600\n"+
+		"\tnsup-period 10\n"+
+		"\tevict-sys-memory-pct 80\n"+
+		"\tstorage-engine device {\n"+
+		"\t\tfile /opt/aerospike/data/users.dat\n"+
+		"\t\tfilesize 4G\n"+
+		"\t\tevict-used-pct 60\n"+
+		"\t}\n"+
+		"}\n")
+	assert.Contains(t, conf, "namespace cache {\n")
+	assert.Contains(t, conf, "\t\tdata-size 2G\n")
+}
+
+func TestWithNamespaceConfigReplacesEarlierEdits(t *testing.T) {
+	conf := renderedConfig(t,
+		WithMemoryWithPersistence("test", 2),
+		WithNamespaceConfig(NamespaceConfig{Name: "test"}),
+	)
+
+	assert.NotContains(t, conf, "filesize")
+	assert.Contains(t, conf, "\t\tdata-size 1G\n")
+}
+
+func TestWithNamespaceConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  NamespaceConfig
+	}{
+		{name: "empty name", cfg: NamespaceConfig{Name: " "}},
+		{name: "unknown engine", cfg: NamespaceConfig{Name: "ns", StorageEngine: "tape"}},
+		{name: "negative replication factor", cfg: NamespaceConfig{Name: "ns", ReplicationFactor: -1}},
+		{name: "negative size", cfg: NamespaceConfig{Name: "ns", SizeGiB: -1}},
+		{name: "fractional TTL", cfg: NamespaceConfig{Name: "ns", DefaultTTL: 1500 * time.Millisecond, NsupPeriod: time.Second}},
+		{name: "TTL without nsup", cfg: NamespaceConfig{Name: "ns", DefaultTTL: time.Hour}},
+		{name: "eviction above 100", cfg: NamespaceConfig{Name: "ns", EvictUsedPct: 101}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newContainerRequest(WithNamespaceConfig(tt.cfg))
+			require.ErrorIs(t, err, ErrInvalidOption)
+		})
+	}
+}
+
+func TestWithNamespaceConfigStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithNamespaceConfig(NamespaceConfig{
+		Name:       "users",
+		DefaultTTL: time.Hour,
+		NsupPeriod: 10 * time.Second,
+	}))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	namespaces, err := container.ListNamespaces(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test", "users"}, namespaces)
+}
+
+func TestWithMemorySize(t *testing.T) {
+	conf := renderedConfig(t, WithMemorySize("test", "2g"))
+
+	assert.Contains(t, conf, "\tstorage-engine memory {\n\t\tdata-size 2G\n\t}\n")
+}
+
+func TestWithMemorySizeValidation(t *testing.T) {
+	for _, size := range []string{"", "0", "0G", "2GB", "-1G", "1.5G"} {
+		_, _, err := newContainerRequest(WithMemorySize("test", size))
+		require.ErrorIsf(t, err, ErrInvalidOption, "size %q", size)
+	}
+
+	_, _, err := newContainerRequest(WithMemorySize(" ", "1G"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithMemoryWithPersistence("test", 1), WithMemorySize("test", "2G"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithNamespaceConfig(NamespaceConfig{Name: "test", StorageEngine: StorageDevice}), WithMemorySize("test", "2G"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithMemorySizeStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithMemorySize("test", "2G"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	stats, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "2147483648", stats["storage-engine.data-size"])
+}
+
+func TestWithStorageEngineDevice(t *testing.T) {
+	conf := renderedConfig(t, WithStorageEngineDevice("test", "4G"))
+
+	assert.Contains(t, conf, "\tstorage-engine device {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 4G\n\t}\n")
+	assert.NotContains(t, conf, "storage-engine memory")
+
+	_, _, err := newContainerRequest(WithStorageEngineDevice("test", "4 gigs"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithStorageEngineDeviceStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithStorageEngineDevice("test", "1G"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	stats, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "device", stats["storage-engine"])
+}
+
+func TestWithNamespaces(t *testing.T) {
+	conf := renderedConfig(t, WithNamespace("main"), WithNamespaces("cache", " analytics ", "main"))
+
+	assert.Equal(t, 1, strings.Count(conf, "namespace main {\n"))
+	assert.Contains(t, conf, "namespace cache {\n")
+	assert.Contains(t, conf, "namespace analytics {\n")
+	assert.NotContains(t, conf, "namespace test {\n")
+
+	_, _, err := newContainerRequest(WithNamespaces())
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithNamespaces("cache", ""))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithNamespacesStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithNamespaces("cache"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	namespaces, err := container.ListNamespaces(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cache", "test"}, namespaces)
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	for _, namespace := range namespaces {
+		key, err := aerospike.NewKey(namespace, "set", "key")
+		require.NoError(t, err)
+		require.NoErrorf(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}), "failed to write to %s", namespace)
+	}
+}
+
+func TestWithReplicationFactor(t *testing.T) {
+	conf := renderedConfig(t, WithReplicationFactor("test", 2))
+
+	assert.Contains(t, conf, "namespace test {\n\treplication-factor 2\n")
+
+	_, _, err := newContainerRequest(WithReplicationFactor("test", 0))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithReplicationFactorStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithReplicationFactor("test", 2))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	stats, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "2", stats["replication-factor"])
+}
+
+func TestWithDefaultTTL(t *testing.T) {
+	conf := renderedConfig(t, WithDefaultTTL("test", 30))
+
+	assert.Contains(t, conf, "\tdefault-ttl 30\n")
+	assert.Contains(t, conf, "\tallow-ttl-without-nsup true\n")
+
+	conf = renderedConfig(t, WithNamespaceConfig(NamespaceConfig{Name: "test", NsupPeriod: time.Second}), WithDefaultTTL("test", 30))
+	assert.Contains(t, conf, "\tdefault-ttl 30\n")
+	assert.NotContains(t, conf, "allow-ttl-without-nsup")
+
+	conf = renderedConfig(t, WithDefaultTTL("test", 0))
+	assert.Contains(t, conf, "\tdefault-ttl 0\n")
+	assert.NotContains(t, conf, "allow-ttl-without-nsup")
+
+	_, _, err := newContainerRequest(WithDefaultTTL("test", -1))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithDefaultTTLExpiresRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithDefaultTTL("test", 2), WithNsupPeriod("test", 1))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "ttl", "key")
+	require.NoError(t, err)
+	policy := aerospike.NewWritePolicy(0, aerospike.TTLServerDefault)
+	require.NoError(t, client.Put(policy, key, aerospike.BinMap{"bin": "value"}))
+
+	exists, err := client.Exists(nil, key)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.Eventually(t, func() bool {
+		exists, err := client.Exists(nil, key)
+		return err == nil && !exists
+	}, 10*time.Second, 200*time.Millisecond)
+}
+
+func TestWithRackID(t *testing.T) {
+	conf := renderedConfig(t, WithRackID("test", 2))
+
+	assert.Contains(t, conf, "\track-id 2\n")
+
+	_, _, err := newContainerRequest(WithRackID("test", -1))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithCompression(t *testing.T) {
+	conf := renderedConfig(t, WithEnterpriseEdition(), WithStorageEngineDevice("test", "4G"), WithCompression("test", "zstd"))
+
+	assert.Contains(t, conf, "\tstorage-engine device {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 4G\n\t\tcompression zstd\n\t}\n")
+
+	_, _, err := newContainerRequest(WithCompression("test", "zstd"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "enterprise")
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithCompression("test", "gzip"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithCompressionCompressesRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithCompression("test", "zstd"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "zstd", config["storage-engine.compression"])
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	for i := range 100 {
+		key, err := aerospike.NewKey("test", "compression", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": strings.Repeat("compressible ", 100)}))
+	}
+
+	ratio, err := container.CompressionRatio(ctx, "test")
+	require.NoError(t, err)
+	assert.Less(t, ratio, 0.5)
+}
+
+func TestWithStopWritesPct(t *testing.T) {
+	conf := renderedConfig(t, WithStopWritesPct("test", 5))
+	assert.Contains(t, conf, "\tstorage-engine memory {\n\t\tdata-size 1G\n\t\tstop-writes-used-pct 5\n\t}\n")
+
+	conf = renderedConfig(t, WithStorageEngineDevice("test", "4G"), WithStopWritesPct("test", 0))
+	assert.Contains(t, conf, "\t\tfilesize 4G\n\t\tstop-writes-used-pct 0\n")
+
+	for _, pct := range []int{-1, 101} {
+		_, _, err := newContainerRequest(WithStopWritesPct("test", pct))
+		require.ErrorIsf(t, err, ErrInvalidOption, "pct %d", pct)
+	}
+}
+
+func TestWithStopWritesPctConfirmsValue(t *testing.T) {
+	req, _, err := newContainerRequest(WithStopWritesPct("test", 5))
+	require.NoError(t, err)
+	require.Len(t, req.LifecycleHooks, 1)
+	hook := req.LifecycleHooks[0].PostReadies[0]
+
+	var commands []string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		commands = append(commands, cmd[len(cmd)-1])
+		return 0, "replication-factor=1;storage-engine=memory;storage-engine.stop-writes-used-pct=5", nil
+	}}
+	require.NoError(t, hook(context.Background(), c))
+	assert.Equal(t, []string{"get-config:context=namespace;id=test"}, commands)
+
+	c = &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+		return 0, "replication-factor=1;storage-engine=memory;storage-engine.stop-writes-used-pct=70", nil
+	}}
+	require.ErrorIs(t, hook(context.Background(), c), ErrConfigRejected)
+}
+
+func TestWithStopWritesPctRejectsWrites(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithStopWritesPct("test", 1))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "1", config["storage-engine.stop-writes-used-pct"])
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+
+	// 1% of the 1G namespace is about 10M; write it at a pace the server's
+	// periodic stop-writes check can keep up with.
+	value := strings.Repeat("x", 64<<10)
+	var writeErr aerospike.Error
+	for i := 0; writeErr == nil && i < 1000; i++ {
+		key, err := aerospike.NewKey("test", "stop-writes", i)
+		require.NoError(t, err)
+		writeErr = client.Put(nil, key, aerospike.BinMap{"bin": value})
+		if i%10 == 9 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	require.Error(t, writeErr)
+	assert.True(t, writeErr.Matches(types.SERVER_MEM_ERROR), "unexpected error: %v", writeErr)
+
+	stats, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "true", stats["stop_writes"])
+}
+
+func TestWithMaxRecordSize(t *testing.T) {
+	conf := renderedConfig(t, WithMaxRecordSize("test", "4m"))
+	assert.Contains(t, conf, "\tmax-record-size 4M\n")
+
+	for _, size := range []string{"", "0", "9M", "1G", "99999999999999999999T"} {
+		_, _, err := newContainerRequest(WithMaxRecordSize("test", size))
+		require.ErrorIsf(t, err, ErrInvalidOption, "size %q", size)
+	}
+}
+
+func TestWithWriteBlockSize(t *testing.T) {
+	conf := renderedConfig(t, WithWriteBlockSize("test", "4m"))
+	assert.Contains(t, conf, "\tmax-record-size 4M\n")
+	assert.NotContains(t, conf, "write-block-size")
+
+	conf = renderedConfig(t, WithImage("aerospike/aerospike-server:7.0"), WithWriteBlockSize("test", "1M"))
+	assert.Contains(t, conf, "\tstorage-engine memory {\n\t\tdata-size 1G\n\t\twrite-block-size 1M\n")
+	assert.NotContains(t, conf, "max-record-size")
+
+	conf = renderedConfig(t, WithImage("aerospike/aerospike-server:6.4"), WithStorageEngineDevice("test", "1G"), WithWriteBlockSize("test", "128K"))
+	assert.Contains(t, conf, "\t\twrite-block-size 128K\n")
+
+	_, _, err := newContainerRequest(WithImage("aerospike/aerospike-server:6.4"), WithWriteBlockSize("test", "1M"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "device")
+
+	for _, size := range []string{"", "512", "3M", "16M", "99999999999999999999T"} {
+		_, _, err := newContainerRequest(WithWriteBlockSize("test", size))
+		require.ErrorIsf(t, err, ErrInvalidOption, "size %q", size)
+	}
+}
+
+func TestConfigSizeBytes(t *testing.T) {
+	assert.Equal(t, int64(512), configSizeBytes("512"))
+	assert.Equal(t, int64(8<<20), configSizeBytes("8M"))
+	assert.Equal(t, int64(2<<40), configSizeBytes("2T"))
+	assert.Equal(t, int64(-1), configSizeBytes("8388608T"))
+}
+
+func TestWithMaxRecordSizeLimitsRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithMaxRecordSize("test", "2M"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "2097152", config["max-record-size"])
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "large", "key")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": make([]byte, 3<<19)}))
+
+	aerr := client.Put(nil, key, aerospike.BinMap{"bin": make([]byte, 3<<20)})
+	require.Error(t, aerr)
+	assert.True(t, aerr.Matches(types.RECORD_TOO_BIG), "unexpected error: %v", aerr)
+}
+
+func TestWithWriteBlockSizeLimitsRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithWriteBlockSize("test", "1M"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "1048576", config["max-record-size"])
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "block", "key")
+	require.NoError(t, err)
+
+	aerr := client.Put(nil, key, aerospike.BinMap{"bin": make([]byte, 2<<20)})
+	require.Error(t, aerr)
+	assert.True(t, aerr.Matches(types.RECORD_TOO_BIG), "unexpected error: %v", aerr)
+}
+
+func TestWithSingleBin(t *testing.T) {
+	conf := renderedConfig(t, WithImage("aerospike/aerospike-server:6.4"), WithMemorySize("test", "2G"), WithSingleBin("test"))
+
+	assert.Contains(t, conf, "\tmemory-size 2G\n\tstorage-engine memory\n\tsingle-bin true\n}\n")
+	assert.NotContains(t, conf, "data-size")
+
+	_, _, err := newContainerRequest(WithSingleBin("test"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "7.0")
+
+	_, _, err = newContainerRequest(WithImage("aerospike/aerospike-server:latest"), WithSingleBin("test"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithImage("aerospike/aerospike-server:6.4"), WithStorageEngineDevice("test", "1G"), WithSingleBin("test"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithSingleBinConvertsOtherMemoryNamespaces(t *testing.T) {
+	conf := renderedConfig(t, WithImage("aerospike/aerospike-server:6.4"), WithNamespaces("other"), WithSingleBin("other"))
+
+	// The default namespace stays multi-bin but uses the pre-7.0 syntax too.
+	assert.Contains(t, conf, "namespace test {\n")
+	assert.Contains(t, conf, "\tmemory-size 1G\n\tstorage-engine memory\n}\n")
+	assert.Contains(t, conf, "\tstorage-engine memory\n\tsingle-bin true\n}\n")
+	assert.NotContains(t, conf, "data-size")
+	assert.NotContains(t, conf, "storage-engine memory {")
+	assert.Equal(t, 1, strings.Count(conf, "single-bin"))
+
+	_, _, err := newContainerRequest(WithImage("aerospike/aerospike-server:6.4"), WithNamespaces("other"), WithMemoryWithPersistence("test", 1), WithSingleBin("other"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), `"test"`)
+}
+
+func TestWithSingleBinStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithImage("aerospike/aerospike-server:6.4"), WithSingleBin("test"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "true", config["single-bin"])
+
+	require.NoError(t, container.Seed(ctx, "test", "single", []SeedRecord{{Key: 1, Bins: aerospike.BinMap{"value": 1}}}))
+
+	err = container.Seed(ctx, "test", "single", []SeedRecord{{Key: 2, Bins: aerospike.BinMap{"a": 1, "b": 2}}})
+	var seedErr *SeedError
+	require.ErrorAs(t, err, &seedErr)
+	assert.Equal(t, 0, seedErr.Written)
+}
+
+func TestWithDataInMemory(t *testing.T) {
+	conf := renderedConfig(t, WithStorageEngineDevice("test", "4G"), WithDataInMemory("test", true))
+
+	assert.Contains(t, conf, "\tstorage-engine memory {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 4G\n\t}\n")
+	assert.NotContains(t, conf, "storage-engine device")
+
+	conf = renderedConfig(t, WithMemoryWithPersistence("test", 2), WithDataInMemory("test", false))
+	assert.Contains(t, conf, "\tstorage-engine device {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 2G\n\t}\n")
+	assert.NotContains(t, conf, "storage-engine memory")
+
+	conf = renderedConfig(t, WithStorageEngineDevice("test", "4G"), WithDataInMemory("test", false))
+	assert.Contains(t, conf, "\tstorage-engine device {\n")
+
+	_, _, err := newContainerRequest(WithDataInMemory("test", true))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithDataInMemoryStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithStorageEngineDevice("test", "1G"), WithDataInMemory("test", true))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "memory", config["storage-engine"])
+	assert.Equal(t, "/opt/aerospike/data/test.dat", config["storage-engine.file[0]"])
+}
+
+func TestWithPersistentVolume(t *testing.T) {
+	dir := t.TempDir()
+
+	req, _, err := newContainerRequest(WithNamespace("custom"), WithPersistentVolume(dir))
+	require.NoError(t, err)
+
+	require.NotNil(t, req.HostConfigModifier)
+	var hostConfig container.HostConfig
+	req.HostConfigModifier(&hostConfig)
+	assert.Equal(t, []string{dir + ":/opt/aerospike/data"}, hostConfig.Binds)
+
+	conf := renderedConfig(t, WithNamespace("custom"), WithPersistentVolume(dir))
+	assert.Contains(t, conf, "namespace custom {\n")
+	assert.Contains(t, conf, "\tstorage-engine device {\n\t\tfile /opt/aerospike/data/custom.dat\n\t\tfilesize 1G\n\t}\n")
+
+	conf = renderedConfig(t, WithStorageEngineDevice("test", "4G"), WithPersistentVolume(dir))
+	assert.Contains(t, conf, "\t\tfilesize 4G\n")
+}
+
+func TestWithTmpfsDataDir(t *testing.T) {
+	req, _, err := newContainerRequest(WithTmpfsDataDir(256<<20 + 1))
+	require.NoError(t, err)
+
+	require.NotNil(t, req.HostConfigModifier)
+	var hostConfig container.HostConfig
+	req.HostConfigModifier(&hostConfig)
+	assert.Equal(t, map[string]string{"/opt/aerospike/data": "rw,size=256m"}, hostConfig.Tmpfs)
+
+	conf := renderedConfig(t, WithTmpfsDataDir(256<<20))
+	assert.Contains(t, conf, "\tstorage-engine device {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 256M\n\t}\n")
+
+	conf = renderedConfig(t, WithStorageEngineDevice("test", "128M"), WithTmpfsDataDir(256<<20))
+	assert.Contains(t, conf, "\t\tfilesize 128M\n")
+
+	_, _, err = newContainerRequest(WithTmpfsDataDir(1 << 20))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithTmpfsDataDirStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	c := startContainer(ctx, t, WithTmpfsDataDir(256<<20))
+	t.Cleanup(func() {
+		require.NoErrorf(t, c.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	result, err := runExec(ctx, c, []string{"cat", "/proc/mounts"})
+	require.NoError(t, err)
+	assert.Regexp(t, `(?m)^tmpfs /opt/aerospike/data tmpfs `, result.stdout)
+
+	config, err := c.getConfig(ctx, "namespace", "test")
+	require.NoError(t, err)
+	assert.Equal(t, "device", config["storage-engine"])
+
+	client, err := c.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "tmpfs", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
+
+func TestWithPersistentVolumeValidation(t *testing.T) {
+	_, _, err := newContainerRequest(WithPersistentVolume(""))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithPersistentVolume(filepath.Join(t.TempDir(), "missing")))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, _, err = newContainerRequest(WithPersistentVolume(file))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithPersistentVolumeKeepsData(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	first := startContainer(ctx, t, WithPersistentVolume(dir))
+
+	client, err := first.NewClient(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "persistent", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+	client.Close()
+
+	// Restarting the container keeps the records.
+	stopTimeout := 10 * time.Second
+	require.NoError(t, first.Stop(ctx, &stopTimeout))
+	require.NoError(t, first.Start(ctx))
+
+	client, err = first.NewClient(ctx)
+	require.NoError(t, err)
+	record, err := client.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, "value", record.Bins["bin"])
+	client.Close()
+
+	// So does starting a new container on the same directory.
+	require.NoError(t, first.Terminate(ctx))
+
+	second := startContainer(ctx, t, WithPersistentVolume(dir))
+	t.Cleanup(func() {
+		require.NoErrorf(t, second.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err = second.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	record, err = client.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, "value", record.Bins["bin"])
+}
@@ -0,0 +1,464 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	tclog "github.com/testcontainers/testcontainers-go/log"
+)
+
+// ErrInvalidOption is returned when an option is given a value it cannot use.
+var ErrInvalidOption = errors.New("invalid option")
+
+// maxProtoFdMax is the largest proto-fd-max WithProtoFdMax accepts, the open
+// file limit of containers under a default Docker daemon.
+const maxProtoFdMax = 1048576
+
+// options holds the settings that shape how the Container behaves after it
+// has started, as opposed to the container request itself.
+type options struct {
+	infoTimeout  time.Duration
+	infoRetries  int
+	tendInterval time.Duration
+	readLevel    ConsistencyLevel
+	user         string
+	password     string
+	roles        []roleSpec
+	users        []userSpec
+	tls          *tlsMaterial
+	configFile   string
+	configEdits  []configEdit
+	exporter     *metricsExporter
+	servicePort  int
+	udfs         []schemaUDF
+	setConfigs   []setConfigSpec
+	// noDefaultNamespace leaves the default namespace out of the rendered
+	// configuration.
+	noDefaultNamespace bool
+	// requestModifiers edit the assembled request, in the order they were set.
+	requestModifiers []func(*testcontainers.ContainerRequest)
+	// image, networks and networkAliases record the assembled request, for
+	// the helpers that act on the container after it has started.
+	image          string
+	networks       []string
+	networkAliases map[string][]string
+}
+
+func defaultOptions() options {
+	return options{
+		infoTimeout: defaultInfoTimeout,
+	}
+}
+
+// Option configures the behavior of the Container and its helpers. It
+// satisfies testcontainers.ContainerCustomizer so it can be passed to
+// RunContainer alongside the request options.
+type Option func(*options) error
+
+var _ testcontainers.ContainerCustomizer = Option(nil)
+
+// Customize applies the option on its own, for callers that pass it straight
+// to testcontainers.GenericContainer or call it on a request they build
+// themselves. Only the changes made through hooks, such as those of
+// WithSetConfig and WithTTLSupport, take effect then: they run with the
+// default settings as soon as the container has started, as they did before
+// these options carried settings. RunContainer applies options through the
+// settings it assembles from all of them and does not call Customize.
+func (o Option) Customize(req *testcontainers.GenericContainerRequest) error {
+	settings := defaultOptions()
+	if err := o(&settings); err != nil {
+		return err
+	}
+
+	if len(settings.setConfigs) > 0 {
+		req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+			PostStarts: []testcontainers.ContainerHook{setConfigsHook(settings)},
+		})
+	}
+
+	return nil
+}
+
+// WithInfoTimeout bounds how long each asinfo command run through Exec may
+// take before it is abandoned. Exec has no timeout of its own, so without
+// this a wedged server can hang the whole test run. The default is 10s.
+func WithInfoTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: info timeout must be positive, got %s", ErrInvalidOption, d)
+		}
+		o.infoTimeout = d
+
+		return nil
+	}
+}
+
+// WithInfoRetries retries asinfo commands up to n more times, with a short
+// backoff, when they fail transiently: a timeout or a failure to reach the
+// server. These blips are common right after startup and during migrations.
+// Errors reported by the command itself are never retried. AsInfo returns an
+// empty response as is, as it is a valid answer to many commands, such as
+// sindex-list without indexes; AsInfoNonEmpty retries it as well, and so do the
+// helpers built on commands that always answer, such as Stats and
+// NamespaceStats. The default is 0, which disables retries.
+func WithInfoRetries(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("%w: info retries must not be negative, got %d", ErrInvalidOption, n)
+		}
+		o.infoRetries = n
+
+		return nil
+	}
+}
+
+// WithClientTendInterval sets how often the clients created by the Container
+// helpers poll the cluster for topology changes. The client default is 1s; a
+// shorter interval lets failover tests notice node changes sooner at the cost
+// of more info traffic to the server.
+func WithClientTendInterval(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: tend interval must be positive, got %s", ErrInvalidOption, d)
+		}
+		o.tendInterval = d
+
+		return nil
+	}
+}
+
+// ConsistencyLevel selects how many replicas an AP-mode read consults.
+type ConsistencyLevel int
+
+const (
+	// ConsistencyOne reads from a single replica, normally the master. This is
+	// the default and the fastest option.
+	ConsistencyOne ConsistencyLevel = iota
+	// ConsistencyAll reads from every replica and returns the most recent
+	// version, which exposes replicas that disagree at the cost of one round
+	// trip per replica.
+	ConsistencyAll
+)
+
+// WithReadConsistency sets the consistency level the Container's read helpers
+// use for single-record and batch reads. The default is ConsistencyOne. Scans
+// and queries always read the master copy and are not affected.
+func WithReadConsistency(level ConsistencyLevel) Option {
+	return func(o *options) error {
+		if level != ConsistencyOne && level != ConsistencyAll {
+			return fmt.Errorf("%w: unknown read consistency level %d", ErrInvalidOption, level)
+		}
+		o.readLevel = level
+
+		return nil
+	}
+}
+
+// WithTelemetryDisabled states that the container must not send usage
+// telemetry, as required in air-gapped CI. The telemetry agent only shipped
+// with early community releases; the server images this package supports do
+// not include it, so there is nothing to switch off and the option logs that
+// it is a no-op. It exists so suites can declare the requirement explicitly.
+func WithTelemetryDisabled() Option {
+	return func(*options) error {
+		tclog.Printf("WithTelemetryDisabled: Aerospike server images do not ship a telemetry agent, nothing to disable")
+		return nil
+	}
+}
+
+// WithDebugAllocations turns on the server's memory allocation tracking
+// (debug-allocations all) to help chase memory growth during soak tests.
+// Tracking is only meaningful on enterprise debug builds, so a warning is
+// logged when the image does not look like an enterprise image. The tracked
+// allocations can then be inspected with AsInfo.
+//
+// The setting is static, so this renders a server configuration file in
+// place of the image defaults.
+func WithDebugAllocations() Option {
+	return func(o *options) error {
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				tclog.Printf("WithDebugAllocations: image %q is not an enterprise image, allocation tracking may be unavailable", req.Image)
+			}
+			cfg.service().set("debug-allocations", "all")
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithProtoFdMax sets the maximum number of client connections the server
+// accepts, proto-fd-max, in place of the default of 15000, for load tests
+// whose code under test keeps large client pools. n must be between 1 and
+// maxProtoFdMax, the open file limit Docker gives containers by default. The
+// value is confirmed with get-config once the server is ready.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithProtoFdMax(n int) Option {
+	return func(o *options) error {
+		if n < 1 || n > maxProtoFdMax {
+			return fmt.Errorf("%w: proto-fd-max must be between 1 and %d, got %d", ErrInvalidOption, maxProtoFdMax, n)
+		}
+		value := strconv.Itoa(n)
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			cfg.service().set("proto-fd-max", value)
+
+			req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						// o holds every option by the time the hook runs.
+						config, err := Container{Container: c, settings: *o}.getConfig(ctx, "service", "")
+						if err != nil {
+							return err
+						}
+						if got := config["proto-fd-max"]; got != value {
+							return fmt.Errorf("%w: proto-fd-max is %q after setting it to %q", ErrConfigRejected, got, value)
+						}
+						return nil
+					},
+				},
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithServiceThreads sets the number of threads that process client
+// transactions, service-threads, so benchmarks run with the same concurrency
+// as production. The server default is five per CPU. SetServiceThreads
+// changes it while the server is running.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithServiceThreads(n int) Option {
+	return withServiceThreadCount("service-threads", n)
+}
+
+// WithBatchIndexThreads sets the number of threads that process batch
+// requests, batch-index-threads, so benchmarks that rely on batch reads run
+// with the same concurrency as production. The server default is one per CPU.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithBatchIndexThreads(n int) Option {
+	return withServiceThreadCount("batch-index-threads", n)
+}
+
+// withServiceThreadCount sets the thread count param of the service stanza.
+func withServiceThreadCount(param string, n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: %s must be positive, got %d", ErrInvalidOption, param, n)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.service().set(param, strconv.Itoa(n))
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithNodeID sets the node ID of the server, node-id, in place of the one it
+// derives from its MAC address and port, so cluster tests and rosters of
+// strong-consistency namespaces can refer to nodes by predictable IDs. id is
+// a non-zero number of up to 16 hexadecimal digits, such as "A1"; the server
+// reports it in upper case without leading zeros, as info "node" does. Every
+// node of a cluster needs its own ID, so set it per node with
+// WithClusterNode.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithNodeID(id string) Option {
+	return func(o *options) error {
+		id = strings.TrimSpace(id)
+		n, err := strconv.ParseUint(id, 16, 64)
+		if err != nil || n == 0 {
+			return fmt.Errorf("%w: node ID must be a non-zero hexadecimal number of up to 16 digits, got %q", ErrInvalidOption, id)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			cfg.service().set("node-id", strings.ToUpper(strconv.FormatUint(n, 16)))
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithMemoryWithPersistence stores namespace in memory backed by a file of
+// fileSizeGiB gibibytes, so reads are served from memory while every write is
+// also persisted. Data survives stopping and starting the container, but not
+// terminating it, as the file lives inside the container. Memory storage
+// with a persistence file requires server 7.0 or later.
+func WithMemoryWithPersistence(namespace string, fileSizeGiB int) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+		if fileSizeGiB <= 0 {
+			return fmt.Errorf("%w: persistence file size must be positive, got %d", ErrInvalidOption, fileSizeGiB)
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, _ *testcontainers.GenericContainerRequest) error {
+			engine := cfg.storageEngine(namespace, "memory")
+			// The file size determines the namespace size.
+			engine.params = nil
+			engine.set("file", dataDir+"/"+namespace+".dat")
+			engine.set("filesize", strconv.Itoa(fileSizeGiB)+"G")
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithNoDefaultNamespace leaves the default namespace, "test" or the one set
+// with WithNamespace, out of the configuration, so the server only has the
+// namespaces defined with WithNamespaces or WithNamespaceConfig and asserting
+// on the namespace list holds no surprise. The built-in wait strategy then
+// checks the first of them. Options that act on the default namespace, such
+// as WithPersistentVolume, bring it back. It has no effect with
+// WithConfigFile, whose file is used as is.
+//
+// This renders a server configuration file in place of the image defaults.
+// Starting without any namespace is rejected with ErrInvalidOption, as the
+// server refuses to.
+func WithNoDefaultNamespace() Option {
+	return func(o *options) error {
+		o.noDefaultNamespace = true
+		return nil
+	}
+}
+
+// WithRequestModifier calls fn with the container request once every other
+// option has been applied and the server configuration rendered, so it sees
+// and can change the final image, command, files, environment and wait
+// strategy. It is an escape hatch for settings the options do not cover;
+// edits that contradict the options, such as replacing the command that
+// points asd at the rendered configuration, are not checked and may leave
+// the server unable to start. Modifiers run in the order they were given.
+func WithRequestModifier(fn func(*testcontainers.ContainerRequest)) Option {
+	return func(o *options) error {
+		if fn == nil {
+			return fmt.Errorf("%w: request modifier is nil", ErrInvalidOption)
+		}
+		o.requestModifiers = append(o.requestModifiers, fn)
+		return nil
+	}
+}
+
+// WithConfigFile starts the server with the aerospike.conf at path, copied
+// into the container, for scenarios the options do not cover. The file is
+// used as is: options that only work through the image defaults, such as
+// WithNamespace and WithLogLevel, have no effect, and options that render a
+// configuration themselves, such as WithNamespaceConfig or WithTLS, are
+// rejected. The file must listen for clients on port 3000, or on the port set
+// with WithServicePort.
+func WithConfigFile(path string) Option {
+	return func(o *options) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("%w: failed to open config file: %w", ErrInvalidOption, err)
+		}
+		defer func() { _ = f.Close() }()
+
+		info, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("%w: failed to stat config file: %w", ErrInvalidOption, err)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%w: config file %q is not a regular file", ErrInvalidOption, path)
+		}
+		o.configFile = path
+
+		return nil
+	}
+}
+
+// WithServicePort tells the package that the server listens for clients on
+// port inside the container instead of 3000, as a configuration file set with
+// WithConfigFile may do. The port is exposed in place of 3000, and ServicePort,
+// the wait strategy, asinfo and the bundled tools all use it. Without
+// WithConfigFile the rendered configuration listens on port as well.
+func WithServicePort(port int) Option {
+	return func(o *options) error {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("%w: service port must be between 1 and 65535, got %d", ErrInvalidOption, port)
+		}
+		o.servicePort = port
+		return nil
+	}
+}
+
+// WithFabricPort maps the fabric port, 3001, to the host and has the server
+// listen for fabric traffic on every interface, so clustering problems can be
+// debugged from the test process. FabricPort returns the mapped port. The
+// readiness check only uses the service port and is not affected.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithFabricPort() Option {
+	return func(o *options) error {
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			cfg.network().child("fabric").set("address", "any")
+			exposePort(req, aerospikeFabricPort)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithHeartbeatPort maps the mesh heartbeat port, 3002, to the host and has
+// the server listen for heartbeats on every interface rather than only on the
+// loopback interface, so clustering problems can be debugged from the test
+// process. HeartbeatPort returns the mapped port. The readiness check only
+// uses the service port and is not affected.
+//
+// This renders a server configuration file in place of the image defaults.
+func WithHeartbeatPort() Option {
+	return func(o *options) error {
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			cfg.network().child("heartbeat").set("address", "any")
+			exposePort(req, aerospikeHeartbeatPort)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// exposePort adds port to the ports exposed by req unless it is already there.
+func exposePort(req *testcontainers.GenericContainerRequest, port string) {
+	if !slices.Contains(req.ExposedPorts, port) {
+		req.ExposedPorts = append(req.ExposedPorts, port)
+	}
+}
+
+// serviceContainerPort returns the port the server listens on for clients
+// inside the container.
+func (o options) serviceContainerPort() int {
+	if o.servicePort != 0 {
+		return o.servicePort
+	}
+	return 3000
+}
+
+// servicePortSpec returns the service port in the form used to expose and
+// look up ports, such as "3000/tcp".
+func (o options) servicePortSpec() string {
+	if o.servicePort != 0 {
+		return strconv.Itoa(o.servicePort) + "/tcp"
+	}
+	return aerospikeServicePort
+}
@@ -0,0 +1,352 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+func TestWithClientTendInterval(t *testing.T) {
+	_, settings, err := newContainerRequest(WithClientTendInterval(50 * time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Millisecond, settings.tendInterval)
+
+	_, _, err = newContainerRequest(WithClientTendInterval(0))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithMemoryWithPersistence(t *testing.T) {
+	conf := renderedConfig(t, WithMemoryWithPersistence("test", 2))
+
+	assert.Contains(t, conf, "\tstorage-engine memory {\n\t\tfile /opt/aerospike/data/test.dat\n\t\tfilesize 2G\n\t}\n")
+	assert.NotContains(t, conf, "data-size")
+}
+
+func TestWithMemoryWithPersistenceValidation(t *testing.T) {
+	_, _, err := newContainerRequest(WithMemoryWithPersistence("test", 0))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithMemoryWithPersistence("", 1))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithMemoryWithPersistenceSurvivesRestart(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithMemoryWithPersistence("test", 1))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	key, err := aerospike.NewKey("test", "persisted", "key")
+	require.NoError(t, err)
+	require.NoError(t, newAerospikeClient(t, host, port).Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	require.NoError(t, container.Stop(ctx, nil))
+	require.NoError(t, container.Start(ctx))
+
+	// The mapped port changes across a restart.
+	port, err = container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	record, err := newAerospikeClient(t, host, port).Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, "value", record.Bins["bin"])
+}
+
+func TestWithTelemetryDisabledIsNoOp(t *testing.T) {
+	plain, plainSettings, err := newContainerRequest()
+	require.NoError(t, err)
+
+	req, settings, err := newContainerRequest(WithTelemetryDisabled())
+	require.NoError(t, err)
+
+	assert.Equal(t, plain.Env, req.Env)
+	assert.Equal(t, plain.Cmd, req.Cmd)
+	assert.Empty(t, req.Files)
+	assert.Equal(t, plainSettings.infoTimeout, settings.infoTimeout)
+}
+
+func TestWithReadConsistency(t *testing.T) {
+	_, settings, err := newContainerRequest()
+	require.NoError(t, err)
+	assert.Equal(t, ConsistencyOne, settings.readLevel)
+
+	_, settings, err = newContainerRequest(WithReadConsistency(ConsistencyAll))
+	require.NoError(t, err)
+
+	policy := aerospike.NewPolicy()
+	require.NoError(t, Container{settings: settings}.applyReadPolicy(context.Background(), policy))
+	assert.Equal(t, aerospike.ReadModeAPAll, policy.ReadModeAP)
+
+	_, _, err = newContainerRequest(WithReadConsistency(ConsistencyLevel(7)))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithServicePort(t *testing.T) {
+	req, settings, err := newContainerRequest(WithServicePort(4000))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"4000/tcp", aerospikeInfoPort}, req.ExposedPorts)
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "4000/tcp", strategy.port)
+	assert.Equal(t, 4000, settings.serviceContainerPort())
+
+	conf := renderedConfig(t, WithServicePort(4000))
+	assert.Contains(t, conf, "\tservice {\n\t\taddress any\n\t\tport 4000\n\t}\n")
+
+	for _, port := range []int{0, -1, 65536} {
+		_, _, err := newContainerRequest(WithServicePort(port))
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestWithServicePortAppliesToAsinfo(t *testing.T) {
+	settings := defaultOptions()
+	require.NoError(t, WithServicePort(4000)(&settings))
+
+	var gotCmd []string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			gotCmd = cmd
+			return 0, "ok", nil
+		}},
+		settings: settings,
+	}
+
+	_, err := c.AsInfo(context.Background(), "status")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asinfo", "-p", "4000", "-v", "status"}, gotCmd)
+}
+
+func TestWithServicePortMapsPort(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithServicePort(4000))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	mapped, err := container.MappedPort(ctx, "4000/tcp")
+	require.NoError(t, err)
+	port, err := container.ServicePort(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int(mapped.Num()), port)
+
+	status, err := container.AsInfo(ctx, "status")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", status)
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+	assert.True(t, client.IsConnected())
+}
+
+func TestWithFabricAndHeartbeatPorts(t *testing.T) {
+	req, _, err := newContainerRequest(WithFabricPort(), WithHeartbeatPort(), WithHeartbeatPort())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{aerospikeServicePort, aerospikeInfoPort, aerospikeFabricPort, aerospikeHeartbeatPort}, req.ExposedPorts)
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, aerospikeServicePort, strategy.port)
+
+	conf := renderedConfig(t, WithFabricPort(), WithHeartbeatPort())
+	assert.Contains(t, conf, "\theartbeat {\n\t\tmode mesh\n\t\taddress any\n")
+	assert.Contains(t, conf, "\tfabric {\n\t\taddress any\n\t\tport 3001\n\t}\n")
+}
+
+func TestFabricAndHeartbeatPortsAreMapped(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithFabricPort(), WithHeartbeatPort())
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	fabric, err := container.FabricPort(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, fabric)
+	heartbeat, err := container.HeartbeatPort(ctx)
+	require.NoError(t, err)
+	assert.Positive(t, heartbeat)
+}
+
+func TestWithProtoFdMax(t *testing.T) {
+	conf := renderedConfig(t, WithProtoFdMax(50000))
+	assert.Contains(t, conf, "service {\n\tproto-fd-max 50000\n")
+
+	for _, n := range []int{0, -1, maxProtoFdMax + 1} {
+		_, _, err := newContainerRequest(WithProtoFdMax(n))
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestWithProtoFdMaxConfirmsValue(t *testing.T) {
+	req, _, err := newContainerRequest(WithProtoFdMax(50000))
+	require.NoError(t, err)
+	require.Len(t, req.LifecycleHooks, 1)
+	hook := req.LifecycleHooks[0].PostReadies[0]
+
+	var commands []string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		commands = append(commands, cmd[len(cmd)-1])
+		return 0, "proto-fd-max=50000;service-threads=8", nil
+	}}
+	require.NoError(t, hook(context.Background(), c))
+	assert.Equal(t, []string{"get-config:context=service"}, commands)
+
+	c = &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+		return 0, "proto-fd-max=15000;service-threads=8", nil
+	}}
+	require.ErrorIs(t, hook(context.Background(), c), ErrConfigRejected)
+}
+
+func TestWithProtoFdMaxStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithProtoFdMax(50000))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "service", "")
+	require.NoError(t, err)
+	assert.Equal(t, "50000", config["proto-fd-max"])
+}
+
+func TestWithServiceAndBatchIndexThreads(t *testing.T) {
+	conf := renderedConfig(t, WithServiceThreads(16), WithBatchIndexThreads(4))
+	assert.Contains(t, conf, "\tservice-threads 16\n")
+	assert.Contains(t, conf, "\tbatch-index-threads 4\n")
+
+	for _, opt := range []Option{WithServiceThreads(0), WithBatchIndexThreads(-1)} {
+		_, _, err := newContainerRequest(opt)
+		require.ErrorIs(t, err, ErrInvalidOption)
+	}
+}
+
+func TestWithServiceAndBatchIndexThreadsAreApplied(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithServiceThreads(6), WithBatchIndexThreads(3))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	config, err := container.getConfig(ctx, "service", "")
+	require.NoError(t, err)
+	assert.Equal(t, "6", config["service-threads"])
+	assert.Equal(t, "3", config["batch-index-threads"])
+}
+
+func TestWithNodeID(t *testing.T) {
+	conf := renderedConfig(t, WithNodeID("00a1"))
+	assert.Contains(t, conf, "\tnode-id A1\n")
+
+	for _, id := range []string{"", "0", "xyz", "-1", "0x1", "12345678901234567"} {
+		_, _, err := newContainerRequest(WithNodeID(id))
+		require.ErrorIsf(t, err, ErrInvalidOption, "id %q", id)
+	}
+}
+
+func TestWithNodeIDIsReported(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithNodeID("a1"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	node, err := container.AsInfo(ctx, "node")
+	require.NoError(t, err)
+	assert.Equal(t, "A1", node)
+}
+
+func TestWithNoDefaultNamespace(t *testing.T) {
+	opts := []testcontainers.ContainerCustomizer{WithNoDefaultNamespace(), WithNamespaces("users", "orders")}
+
+	conf := renderedConfig(t, opts...)
+	assert.NotContains(t, conf, "namespace test {")
+	assert.Contains(t, conf, "namespace users {")
+	assert.Contains(t, conf, "namespace orders {")
+
+	req, _, err := newContainerRequest(opts...)
+	require.NoError(t, err)
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "users", strategy.namespace)
+
+	_, _, err = newContainerRequest(WithNoDefaultNamespace())
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithNoDefaultNamespaceStartsServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithNoDefaultNamespace(), WithNamespaceConfig(NamespaceConfig{Name: "users"}))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	namespaces, err := container.AsInfo(ctx, "namespaces")
+	require.NoError(t, err)
+	assert.Equal(t, "users", namespaces)
+
+	_, err = container.NamespaceStats(ctx, "test")
+	require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+}
+
+func TestWithRequestModifierRunsLast(t *testing.T) {
+	var calls []string
+	req, _, err := newContainerRequest(
+		WithRequestModifier(func(req *testcontainers.ContainerRequest) {
+			calls = append(calls, "first")
+			// Options given after the modifier have already been applied.
+			assert.Equal(t, "custom", req.Env["NAMESPACE"])
+			assert.Contains(t, req.Cmd, serverConfigPath)
+			req.Env["EXTRA"] = "1"
+		}),
+		WithNamespace("custom"),
+		WithServiceThreads(4),
+		WithRequestModifier(func(req *testcontainers.ContainerRequest) {
+			calls = append(calls, "second")
+			req.Image = "registry.example.com/aerospike-server:8.0"
+		}),
+		WithImage("aerospike/aerospike-server:7.2"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, "1", req.Env["EXTRA"])
+	assert.Equal(t, "registry.example.com/aerospike-server:8.0", req.Image)
+
+	_, _, err = newContainerRequest(WithRequestModifier(nil))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -0,0 +1,216 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+)
+
+// partitionCount is the fixed number of partitions in every Aerospike namespace.
+const partitionCount = 4096
+
+// ErrUnexpectedInfoResponse is returned when an asinfo response cannot be parsed.
+var ErrUnexpectedInfoResponse = errors.New("unexpected asinfo response")
+
+// PartitionOwnership describes one partition as seen by the node running in
+// the container. In a cluster, query each node to build the complete picture.
+type PartitionOwnership struct {
+	// State is the partition state on this node: "S" (sync), "D" (desync),
+	// "Z" (zombie) or "A" (absent).
+	State string
+	// Master is the node ID of the partition's working master.
+	Master string
+	// Replica is this node's position in the partition's succession list.
+	// Position 0 is the master; positions at or beyond Replicas mean this node
+	// holds no copy.
+	Replica int
+	// Replicas is the number of copies the namespace keeps of each partition.
+	Replicas int
+	// Emigrates and Immigrates count the migrations still pending for the
+	// partition on this node.
+	Emigrates  int
+	Immigrates int
+	// Records is the number of records this node holds for the partition.
+	Records int64
+}
+
+// IsMaster reports whether this node is the partition's master.
+func (p PartitionOwnership) IsMaster() bool {
+	return p.Replica == 0
+}
+
+// IsReplica reports whether this node holds a copy of the partition, either
+// as master or as prole.
+func (p PartitionOwnership) IsReplica() bool {
+	return p.Replica < p.Replicas
+}
+
+// PartitionMap returns the ownership of every partition of namespace, keyed by
+// partition ID, as reported by the "partition-info" info command. Tests can use
+// it to check partition distribution, spot under-replicated partitions during
+// migration, or confirm rack-aware placement.
+func (c Container) PartitionMap(ctx context.Context, namespace string) (map[int]PartitionOwnership, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.AsInfo(ctx, "partition-info")
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := parsePartitionInfo(resp, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("%w: no partitions reported for namespace %q", ErrUnexpectedInfoResponse, namespace)
+	}
+
+	return partitions, nil
+}
+
+// Digest returns the 20-byte digest that identifies key in namespace.set, as
+// computed by the client and server: RIPEMD-160 over the set name, the key's
+// particle type and its value. The set name is omitted from the hash when it is
+// empty. No server round trip is involved.
+func (c Container) Digest(namespace, set string, key any) ([]byte, error) {
+	k, err := newDigestKey(namespace, set, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.Digest(), nil
+}
+
+// PartitionForKey returns the ID of the partition that holds key in
+// namespace.set. The ID is derived from the key's digest exactly as the client
+// and server compute it, so tests can pick keys that land on a known partition
+// and look up its owner with PartitionMap. The set is part of the digest, so
+// the same user key maps to different partitions in different sets.
+//
+// An error is returned if the node does not report the partition, for example
+// because the namespace does not exist.
+func (c Container) PartitionForKey(ctx context.Context, namespace, set string, key any) (int, error) {
+	k, err := newDigestKey(namespace, set, key)
+	if err != nil {
+		return 0, err
+	}
+	id := k.PartitionId()
+
+	partitions, err := c.PartitionMap(ctx, k.Namespace())
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := partitions[id]; !ok {
+		return 0, fmt.Errorf("%w: partition %d not reported for namespace %q", ErrUnexpectedInfoResponse, id, k.Namespace())
+	}
+
+	return id, nil
+}
+
+// parsePartitionInfo parses a partition-info response, keeping only the rows
+// for namespace. The first row is a header naming the colon-separated columns;
+// the set of columns varies between server versions, so they are looked up by
+// name and missing ones are left at their zero value.
+func parsePartitionInfo(resp, namespace string) (map[int]PartitionOwnership, error) {
+	rows := strings.Split(strings.TrimSpace(resp), ";")
+	if len(rows) == 0 || !strings.HasPrefix(rows[0], "namespace:") {
+		return nil, fmt.Errorf("%w: partition-info has no header", ErrUnexpectedInfoResponse)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range strings.Split(rows[0], ":") {
+		columns[name] = i
+	}
+	if _, ok := columns["partition"]; !ok {
+		return nil, fmt.Errorf("%w: partition-info header has no partition column", ErrUnexpectedInfoResponse)
+	}
+
+	partitions := make(map[int]PartitionOwnership)
+	for _, row := range rows[1:] {
+		if row == "" {
+			continue
+		}
+		fields := strings.Split(row, ":")
+		if len(fields) != len(columns) {
+			return nil, fmt.Errorf("%w: partition-info row %q has %d fields, expected %d", ErrUnexpectedInfoResponse, row, len(fields), len(columns))
+		}
+		if fields[columns["namespace"]] != namespace {
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return fields[i]
+			}
+			return ""
+		}
+		number := func(name string) (int64, error) {
+			value := field(name)
+			if value == "" {
+				return 0, nil
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("%w: partition-info %s %q is not a number", ErrUnexpectedInfoResponse, name, value)
+			}
+			return n, nil
+		}
+
+		id, err := number("partition")
+		if err != nil {
+			return nil, err
+		}
+		if id < 0 || id >= partitionCount {
+			return nil, fmt.Errorf("%w: partition ID %d out of range", ErrUnexpectedInfoResponse, id)
+		}
+
+		ownership := PartitionOwnership{
+			State:  field("state"),
+			Master: field("working_master"),
+		}
+		numbers := []struct {
+			name string
+			dst  func(int64)
+		}{
+			{"replica", func(n int64) { ownership.Replica = int(n) }},
+			{"n_replicas", func(n int64) { ownership.Replicas = int(n) }},
+			{"emigrates", func(n int64) { ownership.Emigrates = int(n) }},
+			{"immigrates", func(n int64) { ownership.Immigrates = int(n) }},
+			{"records", func(n int64) { ownership.Records = n }},
+		}
+		for _, num := range numbers {
+			n, err := number(num.name)
+			if err != nil {
+				return nil, err
+			}
+			num.dst(n)
+		}
+
+		partitions[int(id)] = ownership
+	}
+
+	return partitions, nil
+}
+
+// newDigestKey builds the client key for namespace.set, which computes the
+// digest, after normalizing the arguments the way the other helpers do.
+func newDigestKey(namespace, set string, key any) (*aerospike.Key, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return nil, err
+	}
+
+	k, aerr := aerospike.NewKey(namespace, strings.TrimSpace(set), key)
+	if aerr != nil {
+		return nil, fmt.Errorf("%w: invalid key %v: %w", ErrInvalidArgument, key, aerr)
+	}
+
+	return k, nil
+}
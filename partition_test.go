@@ -0,0 +1,176 @@
+package aerospike
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/pkg/ripemd160"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const partitionInfoSample = "namespace:partition:state:n_replicas:replica:n_dupl:working_master:emigrates:lead_emigrates:immigrates:records:tombstones:regime:version:final_version;" +
+	"test:0:S:2:0:0:BB9020011AC4202:0:0:0:12:0:0:1.0@1:1.0@1;" +
+	"test:1:S:2:1:0:BB9030011AC4202:3:0:1:7:0:0:1.0@1:1.0@1;" +
+	"cache:0:S:1:0:0:BB9020011AC4202:0:0:0:4:0:0:1.0@1:1.0@1"
+
+func TestParsePartitionInfo(t *testing.T) {
+	partitions, err := parsePartitionInfo(partitionInfoSample, "test")
+	require.NoError(t, err)
+	require.Len(t, partitions, 2)
+
+	assert.Equal(t, PartitionOwnership{
+		State:    "S",
+		Master:   "BB9020011AC4202",
+		Replica:  0,
+		Replicas: 2,
+		Records:  12,
+	}, partitions[0])
+	assert.True(t, partitions[0].IsMaster())
+
+	assert.Equal(t, "BB9030011AC4202", partitions[1].Master)
+	assert.Equal(t, 3, partitions[1].Emigrates)
+	assert.Equal(t, 1, partitions[1].Immigrates)
+	assert.False(t, partitions[1].IsMaster())
+	assert.True(t, partitions[1].IsReplica())
+}
+
+func TestParsePartitionInfoToleratesMissingColumns(t *testing.T) {
+	partitions, err := parsePartitionInfo("namespace:partition:state:replica:working_master;test:5:D:0:BB9", "test")
+	require.NoError(t, err)
+
+	assert.Equal(t, PartitionOwnership{State: "D", Master: "BB9"}, partitions[5])
+}
+
+func TestParsePartitionInfoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+	}{
+		{name: "no header", resp: "test:0:S"},
+		{name: "no partition column", resp: "namespace:state;test:S"},
+		{name: "short row", resp: "namespace:partition:state;test:0"},
+		{name: "bad number", resp: "namespace:partition:records;test:0:many"},
+		{name: "partition out of range", resp: "namespace:partition;test:4096"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePartitionInfo(tt.resp, "test")
+			require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+		})
+	}
+}
+
+// ripemd160Of returns the RIPEMD-160 hash of the concatenated parts.
+func ripemd160Of(parts ...[]byte) []byte {
+	h := ripemd160.New()
+	for _, p := range parts {
+		_, _ = h.Write(p)
+	}
+	return h.Sum(nil)
+}
+
+func TestDigest(t *testing.T) {
+	var c Container
+
+	// Particle types: 1 is integer (8 bytes, big-endian), 3 is string.
+	integer := make([]byte, 8)
+	binary.BigEndian.PutUint64(integer, 42)
+
+	tests := []struct {
+		name string
+		set  string
+		key  any
+		want []byte
+	}{
+		{name: "string key", set: "users", key: "alice", want: ripemd160Of([]byte("users"), []byte{3}, []byte("alice"))},
+		{name: "integer key", set: "users", key: 42, want: ripemd160Of([]byte("users"), []byte{1}, integer)},
+		{name: "empty set", set: "", key: "alice", want: ripemd160Of([]byte{3}, []byte("alice"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest, err := c.Digest("test", tt.set, tt.key)
+			require.NoError(t, err)
+			assert.Len(t, digest, 20)
+			assert.Equal(t, tt.want, digest)
+		})
+	}
+
+	// The namespace is not part of the digest.
+	a, err := c.Digest("test", "users", "alice")
+	require.NoError(t, err)
+	b, err := c.Digest("other", "users", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	_, err = c.Digest("", "users", "alice")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestPartitionForKeyRejectsInvalidArguments(t *testing.T) {
+	container := Container{Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+		t.Fatal("no info command expected")
+		return 0, "", nil
+	}}, settings: defaultOptions()}
+
+	_, err := container.PartitionForKey(context.Background(), " ", "set", "key")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+
+	_, err = container.PartitionForKey(context.Background(), "test", "set", nil)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestPartitionMap(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	partitions, err := container.PartitionMap(ctx, "test")
+	require.NoError(t, err)
+	require.Len(t, partitions, partitionCount)
+
+	// A single node masters every partition.
+	for id, p := range partitions {
+		assert.Truef(t, p.IsMaster(), "partition %d should be mastered by the only node", id)
+	}
+}
+
+func TestPartitionForKey(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	key, err := aerospike.NewKey("test", "partitioned", "user-1")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	id, err := container.PartitionForKey(ctx, "test", "partitioned", "user-1")
+	require.NoError(t, err)
+
+	// The server must have stored the record in the partition we computed.
+	partitions, err := container.PartitionMap(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), partitions[id].Records)
+}
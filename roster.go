@@ -0,0 +1,178 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// rosterSetupTimeout bounds each wait of the roster setup run by
+// WithStrongConsistency.
+const rosterSetupTimeout = 30 * time.Second
+
+// WithStrongConsistency enables strong consistency for namespace and, once the
+// server is ready, sets the namespace roster to the nodes it observes and
+// reclusters, so the namespace accepts reads and writes instead of waiting for
+// a roster. The container is only reported ready when no partition is
+// unavailable.
+//
+// Strong consistency is an enterprise feature: it requires
+// WithEnterpriseEdition and, outside the single-node evaluation mode, a feature
+// key that enables it (see WithFeatureKeyFile).
+func WithStrongConsistency(namespace string) Option {
+	return func(o *options) error {
+		namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+		if err != nil {
+			return err
+		}
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				return fmt.Errorf("%w: strong consistency requires an enterprise image, got %q", ErrInvalidOption, req.Image)
+			}
+			cfg.namespace(namespace).set("strong-consistency", "true")
+
+			req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						// o holds every option by the time the hook runs.
+						return setupRoster(ctx, c, *o, namespace)
+					},
+				},
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// setupRoster sets the roster of a strong-consistency namespace to the nodes
+// it currently observes, reclusters and waits until every partition is
+// available.
+func setupRoster(ctx context.Context, c testcontainers.Container, settings options, namespace string) error {
+	var observed string
+	err := pollUntil(ctx, rosterSetupTimeout, func(ctx context.Context) (bool, error) {
+		resp, err := execInfo(ctx, c, settings, "roster:namespace="+namespace)
+		if err != nil {
+			return false, err
+		}
+		observed = parseInfoPairs(resp, ":")["observed_nodes"]
+		return observed != "" && observed != "null", nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for namespace %q to observe its nodes", err, namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp, err := execInfo(ctx, c, settings, "roster-set:namespace="+namespace+";nodes="+observed)
+	if err != nil {
+		return err
+	}
+	if resp != "ok" {
+		return fmt.Errorf("%w: roster of namespace %q: %s", ErrConfigRejected, namespace, resp)
+	}
+
+	if err = reclusterAll(ctx, c, settings); err != nil {
+		return err
+	}
+
+	var unavailable string
+	err = pollUntil(ctx, rosterSetupTimeout, func(ctx context.Context) (bool, error) {
+		resp, err := execInfo(ctx, c, settings, "namespace/"+namespace)
+		if err != nil {
+			return false, err
+		}
+		stats := parseInfoPairs(resp, ";")
+		unavailable = stats["unavailable_partitions"]
+		return unavailable == "0" && stats["dead_partitions"] == "0", nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for namespace %q to become available (unavailable_partitions=%s)", err, namespace, unavailable)
+	}
+
+	return err
+}
+
+// reclusterAll asks the cluster c belongs to to recluster, so a new roster
+// takes effect. Only the principal node acts on the request and the others
+// ignore it, so when c is not the principal the request is sent through every
+// peer it knows of as well.
+func reclusterAll(ctx context.Context, c testcontainers.Container, settings options) error {
+	resp, err := execInfo(ctx, c, settings, "recluster:")
+	if err != nil {
+		return err
+	}
+	switch resp {
+	case "ok":
+		return nil
+	case "ignored-by-non-principal":
+	default:
+		return fmt.Errorf("%w: recluster: %s", ErrConfigRejected, resp)
+	}
+
+	peers, err := execInfo(ctx, c, settings, "peers-clear-std")
+	if err != nil {
+		return err
+	}
+	for _, peer := range parsePeers(peers) {
+		resp, err = runInfoAt(ctx, c, settings, peer.host, peer.port, "recluster:")
+		if err != nil {
+			return fmt.Errorf("failed to recluster through peer %s: %w", peer.host, err)
+		}
+		if resp != "ok" && resp != "ignored-by-non-principal" {
+			return fmt.Errorf("%w: recluster through peer %s: %s", ErrConfigRejected, peer.host, resp)
+		}
+	}
+
+	return nil
+}
+
+// peerAddress is the service address of a cluster peer.
+type peerAddress struct {
+	host string
+	port int
+}
+
+// peerPattern matches one peer of a peers-clear-std response, such as
+// "[BB9030011AC4202,,[172.18.0.3]]", capturing its address list.
+var peerPattern = regexp.MustCompile(`\[[0-9A-Fa-f]+,[^,\[\]]*,\[([^\]]*)\]\]`)
+
+// parsePeers returns the first address of every peer in a peers-clear-std
+// response, "<generation>,<default port>,[<peer>,...]". Addresses carry their
+// own port only when it differs from the default.
+func parsePeers(resp string) []peerAddress {
+	fields := strings.SplitN(resp, ",", 3)
+	if len(fields) < 3 {
+		return nil
+	}
+	defaultPort, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil
+	}
+
+	var peers []peerAddress
+	for _, match := range peerPattern.FindAllStringSubmatch(fields[2], -1) {
+		address, _, _ := strings.Cut(match[1], ",")
+		if address == "" {
+			continue
+		}
+		peer := peerAddress{host: address, port: defaultPort}
+		if host, port, ok := strings.Cut(address, ":"); ok {
+			if n, err := strconv.Atoi(port); err == nil {
+				peer = peerAddress{host: host, port: n}
+			}
+		}
+		peers = append(peers, peer)
+	}
+
+	return peers
+}
@@ -0,0 +1,158 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStrongConsistency(t *testing.T) {
+	config := renderedConfig(t, WithEnterpriseEdition(), WithStrongConsistency("test"))
+
+	assert.Contains(t, config, "namespace test {\n")
+	assert.Contains(t, config, "\tstrong-consistency true\n")
+}
+
+func TestWithStrongConsistencyRequiresEnterprise(t *testing.T) {
+	_, _, err := newContainerRequest(WithStrongConsistency("test"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "enterprise")
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithStrongConsistency(" "))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestSetupRoster(t *testing.T) {
+	var commands []string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		command := cmd[len(cmd)-1]
+		commands = append(commands, command)
+		switch {
+		case strings.HasPrefix(command, "roster:"):
+			return 0, "roster=null:pending_roster=null:observed_nodes=BB9020011AC4202", nil
+		case strings.HasPrefix(command, "namespace/"):
+			return 0, "objects=0;unavailable_partitions=0;dead_partitions=0", nil
+		default:
+			return 0, "ok", nil
+		}
+	}}
+
+	require.NoError(t, setupRoster(context.Background(), c, defaultOptions(), "test"))
+	assert.Equal(t, []string{
+		"roster:namespace=test",
+		"roster-set:namespace=test;nodes=BB9020011AC4202",
+		"recluster:",
+		"namespace/test",
+	}, commands)
+}
+
+func TestSetupRosterReclustersThroughPeers(t *testing.T) {
+	// The local node is not the principal, so the recluster goes through its
+	// peers, one of which is.
+	var commands [][]string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		commands = append(commands, cmd)
+		command := cmd[len(cmd)-1]
+		switch {
+		case strings.HasPrefix(command, "roster:"):
+			return 0, "roster=null:pending_roster=null:observed_nodes=BB9030011AC4202,BB9020011AC4202", nil
+		case command == "peers-clear-std":
+			return 0, "6,3000,[[BB9030011AC4202,,[172.18.0.3]],[BB9040011AC4202,,[172.18.0.4:4000]]]", nil
+		case command == "recluster:" && len(cmd) == 3:
+			return 0, "ignored-by-non-principal", nil
+		case strings.HasPrefix(command, "namespace/"):
+			return 0, "objects=0;unavailable_partitions=0;dead_partitions=0", nil
+		default:
+			return 0, "ok", nil
+		}
+	}}
+
+	require.NoError(t, setupRoster(context.Background(), c, defaultOptions(), "test"))
+	require.Len(t, commands, 7)
+	assert.Equal(t, []string{"asinfo", "-v", "recluster:"}, commands[2])
+	assert.Equal(t, []string{"asinfo", "-v", "peers-clear-std"}, commands[3])
+	assert.Equal(t, []string{"asinfo", "-h", "172.18.0.3", "-p", "3000", "-v", "recluster:"}, commands[4])
+	assert.Equal(t, []string{"asinfo", "-h", "172.18.0.4", "-p", "4000", "-v", "recluster:"}, commands[5])
+}
+
+func TestParsePeers(t *testing.T) {
+	assert.Equal(t, []peerAddress{
+		{host: "172.18.0.3", port: 3000},
+		{host: "172.18.0.4", port: 4000},
+	}, parsePeers("6,3000,[[BB9030011AC4202,,[172.18.0.3,10.0.0.3]],[BB9040011AC4202,,[172.18.0.4:4000]]]"))
+	assert.Empty(t, parsePeers("1,3000,[]"))
+	assert.Empty(t, parsePeers(""))
+}
+
+func TestSetupRosterReportsRejection(t *testing.T) {
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		if strings.HasPrefix(cmd[len(cmd)-1], "roster-set:") {
+			return 0, "ERROR::invalid node list", nil
+		}
+		return 0, "roster=null:pending_roster=null:observed_nodes=BB9020011AC4202", nil
+	}}
+
+	require.ErrorIs(t, setupRoster(context.Background(), c, defaultOptions(), "test"), ErrConfigRejected)
+}
+
+func TestWithStrongConsistencyUsesContainerSettings(t *testing.T) {
+	// The roster is set up after the admin user has been provisioned, even
+	// when WithStrongConsistency comes first.
+	req, _, err := newContainerRequest(
+		WithEnterpriseEdition(),
+		WithStrongConsistency("test"),
+		WithSecurity("ops", "secret"),
+		WithServicePort(4000),
+	)
+	require.NoError(t, err)
+
+	hooks := req.LifecycleHooks[len(req.LifecycleHooks)-1].PostReadies
+	require.Len(t, hooks, 1)
+
+	var flags [][]string
+	c := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		flags = append(flags, cmd[:len(cmd)-1])
+		command := cmd[len(cmd)-1]
+		switch {
+		case strings.HasPrefix(command, "roster:"):
+			return 0, "observed_nodes=BB9020011AC4202", nil
+		case strings.HasPrefix(command, "namespace/"):
+			return 0, "unavailable_partitions=0;dead_partitions=0", nil
+		default:
+			return 0, "ok", nil
+		}
+	}}
+	require.NoError(t, hooks[0](context.Background(), c))
+	for _, f := range flags {
+		assert.Equal(t, []string{"asinfo", "-p", "4000", "-U", "ops", "-P", "secret", "-v"}, f)
+	}
+}
+
+func TestWithStrongConsistencyAcceptsWrites(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithStrongConsistency("test"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	resp, err := container.AsInfo(ctx, "namespace/test")
+	require.NoError(t, err)
+	stats := parseInfoPairs(resp, ";")
+	assert.Equal(t, "true", stats["strong-consistency"])
+	assert.Equal(t, "0", stats["unavailable_partitions"])
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "sc", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
@@ -0,0 +1,195 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// forceEvictionTimeout bounds how long ForceEviction waits for the namespace
+// supervisor to finish a cycle.
+const forceEvictionTimeout = 30 * time.Second
+
+var (
+	// ErrConfigRejected is returned when the server refuses a runtime
+	// configuration change or does not report the new value afterwards.
+	ErrConfigRejected = errors.New("config change rejected")
+	// ErrEvictionNotApplicable is returned by ForceEviction when the namespace
+	// supervisor is disabled for the namespace.
+	ErrEvictionNotApplicable = errors.New("eviction not applicable")
+)
+
+// SetConfig changes a dynamic configuration parameter while the server is
+// running by issuing set-config:context=<configContext>;id=<id>;<param>=<value>,
+// so a test can flip a setting mid-run, such as lowering
+// stop-writes-sys-memory-pct to trigger stop-writes. id names the namespace or
+// other subcontext and may be empty for the service context. ErrConfigRejected
+// is returned, with the server's response, when the change is refused.
+func (c Container) SetConfig(ctx context.Context, configContext, id, param, value string) error {
+	if configContext == "" || param == "" {
+		return fmt.Errorf("%w: set-config context and parameter must not be empty", ErrInvalidArgument)
+	}
+	if id == "" && configContext != "service" {
+		return fmt.Errorf("%w: set-config context %q requires an id", ErrInvalidArgument, configContext)
+	}
+
+	return setConfig(ctx, c.Container, c.settings, configContext, id, param, value)
+}
+
+// SetServiceThreads changes the number of service threads while the server is
+// running, so a benchmark can ramp concurrency within a single test. The change
+// is confirmed with get-config before returning.
+func (c Container) SetServiceThreads(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("%w: service-threads must be positive, got %d", ErrInvalidArgument, n)
+	}
+
+	return c.setConfigVerified(ctx, "service", "", "service-threads", strconv.Itoa(n))
+}
+
+// SetMigrateThreads changes the number of threads that migrate partitions
+// between nodes while the server is running. Raising it speeds up recovery
+// after a node leaves or joins; lowering it keeps migrations in flight longer
+// so tests can observe them. The change is confirmed with get-config before
+// returning.
+func (c Container) SetMigrateThreads(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("%w: migrate-threads must be positive, got %d", ErrInvalidArgument, n)
+	}
+
+	return c.setConfigVerified(ctx, "service", "", "migrate-threads", strconv.Itoa(n))
+}
+
+// ForceEviction runs a namespace supervisor cycle for namespace now instead of
+// waiting for the next nsup-period, so expired records are removed and the
+// eviction thresholds are applied straight away. Aerospike has no info command
+// to trigger a cycle, so the period is lowered to one second until the server
+// logs that a cycle has completed and is then restored.
+//
+// The server logs completed cycles at info level, so the nsup log context must
+// log at info or a more verbose level: with WithLogLevel set to warning or
+// above, ForceEviction waits for the cycle until it times out.
+//
+// ErrEvictionNotApplicable is returned when nsup-period is 0, as the
+// supervisor is then disabled and records can neither expire nor be evicted;
+// enable it with WithTTLSupport or NamespaceConfig.NsupPeriod.
+func (c Container) ForceEviction(ctx context.Context, namespace string) error {
+	return c.forceEviction(ctx, namespace, c.waitForNsupCycle)
+}
+
+// setConfigVerified applies param=value with set-config and reads it back with
+// get-config. configContext is the set-config context, such as "service" or
+// "namespace"; id selects the namespace or other subcontext and is left out of
+// the commands when empty.
+func (c Container) setConfigVerified(ctx context.Context, configContext, id, param, value string) error {
+	if err := setConfig(ctx, c.Container, c.settings, configContext, id, param, value); err != nil {
+		return err
+	}
+
+	config, err := c.getConfig(ctx, configContext, id)
+	if err != nil {
+		return err
+	}
+	if got := config[param]; got != value {
+		return fmt.Errorf("%w: %s is %q after setting it to %q", ErrConfigRejected, param, got, value)
+	}
+
+	return nil
+}
+
+// getConfig returns the parsed get-config response for configContext and, when
+// non-empty, id.
+func (c Container) getConfig(ctx context.Context, configContext, id string) (map[string]string, error) {
+	command := "get-config:context=" + configContext
+	if id != "" {
+		command += ";id=" + id
+	}
+
+	resp, err := c.AsInfo(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+	if resp == "" || resp == "error" || strings.HasPrefix(resp, "ERROR") {
+		return nil, fmt.Errorf("%w: get-config %s %s: %s", ErrUnexpectedInfoResponse, configContext, id, resp)
+	}
+
+	return parseInfoPairs(resp, ";"), nil
+}
+
+// setConfig applies param=value with set-config in the given container. id is
+// left out of the command when empty.
+func setConfig(ctx context.Context, c testcontainers.Container, settings options, configContext, id, param, value string) error {
+	command := "set-config:context=" + configContext
+	if id != "" {
+		command += ";id=" + id
+	}
+
+	resp, err := execInfo(ctx, c, settings, command+";"+param+"="+value)
+	if err != nil {
+		return err
+	}
+	if resp != "ok" {
+		return fmt.Errorf("%w: %s=%s: %s", ErrConfigRejected, param, value, resp)
+	}
+
+	return nil
+}
+
+// forceEviction implements ForceEviction, with waitForCycle waiting until a
+// supervisor cycle of namespace that ended after since has been logged.
+func (c Container) forceEviction(ctx context.Context, namespace string, waitForCycle func(ctx context.Context, namespace string, since time.Time) error) (err error) {
+	namespace, err = normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+
+	config, err := c.getConfig(ctx, "namespace", namespace)
+	if err != nil {
+		return err
+	}
+	period, ok := config["nsup-period"]
+	if !ok {
+		return fmt.Errorf("%w: get-config for namespace %q has no nsup-period", ErrUnexpectedInfoResponse, namespace)
+	}
+	if period == "0" {
+		return fmt.Errorf("%w: nsup-period is 0 for namespace %q", ErrEvictionNotApplicable, namespace)
+	}
+
+	since := time.Now()
+	if period != "1" {
+		if err := c.setConfigVerified(ctx, "namespace", namespace, "nsup-period", "1"); err != nil {
+			return err
+		}
+		defer func() {
+			if restoreErr := c.setConfigVerified(ctx, "namespace", namespace, "nsup-period", period); err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+
+	return waitForCycle(ctx, namespace, since)
+}
+
+// waitForNsupCycle waits until the server has logged a completed supervisor
+// cycle for namespace since since. Only the logs written since then are read,
+// so the wait does not slow down as the log grows.
+func (c Container) waitForNsupCycle(ctx context.Context, namespace string, since time.Time) error {
+	marker := "{" + namespace + "} nsup-done"
+	err := pollUntil(ctx, forceEvictionTimeout, func(ctx context.Context) (bool, error) {
+		logs, err := c.logsSince(ctx, since)
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(logs, marker), nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for a supervisor cycle of namespace %q; is the nsup log context at info level?", err, namespace)
+	}
+
+	return err
+}
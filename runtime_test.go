@@ -0,0 +1,224 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configContainer returns a Container that answers set-config with setResp and
+// get-config with getResp, recording the info commands it receives.
+func configContainer(setResp, getResp string, commands *[]string) Container {
+	return Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			command := cmd[len(cmd)-1]
+			*commands = append(*commands, command)
+			if strings.HasPrefix(command, "set-config:") {
+				return 0, setResp, nil
+			}
+			return 0, getResp, nil
+		}},
+		settings: defaultOptions(),
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "", &commands)
+
+	require.NoError(t, c.SetConfig(context.Background(), "namespace", "test", "stop-writes-sys-memory-pct", "50"))
+	require.NoError(t, c.SetConfig(context.Background(), "service", "", "proto-fd-idle-ms", "70000"))
+	assert.Equal(t, []string{
+		"set-config:context=namespace;id=test;stop-writes-sys-memory-pct=50",
+		"set-config:context=service;proto-fd-idle-ms=70000",
+	}, commands)
+}
+
+func TestSetConfigValidatesArguments(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "", &commands)
+
+	require.ErrorIs(t, c.SetConfig(context.Background(), "namespace", "", "nsup-period", "1"), ErrInvalidArgument)
+	require.ErrorIs(t, c.SetConfig(context.Background(), "", "", "nsup-period", "1"), ErrInvalidArgument)
+	require.ErrorIs(t, c.SetConfig(context.Background(), "service", "", "", "1"), ErrInvalidArgument)
+	assert.Empty(t, commands)
+}
+
+func TestSetConfigReportsRejection(t *testing.T) {
+	var commands []string
+	c := configContainer("ERROR:4:invalid value", "", &commands)
+
+	err := c.SetConfig(context.Background(), "namespace", "test", "default-ttl", "-1")
+	require.ErrorIs(t, err, ErrConfigRejected)
+	assert.Contains(t, err.Error(), "ERROR:4:invalid value")
+}
+
+func TestSetServiceThreads(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "service-threads=8;transaction-queues=8", &commands)
+
+	require.NoError(t, c.SetServiceThreads(context.Background(), 8))
+	assert.Equal(t, []string{
+		"set-config:context=service;service-threads=8",
+		"get-config:context=service",
+	}, commands)
+}
+
+func TestSetServiceThreadsRejectsNonPositive(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "", &commands)
+
+	require.ErrorIs(t, c.SetServiceThreads(context.Background(), 0), ErrInvalidArgument)
+	assert.Empty(t, commands)
+}
+
+func TestSetServiceThreadsReportsRejection(t *testing.T) {
+	var commands []string
+	c := configContainer("error", "", &commands)
+
+	err := c.SetServiceThreads(context.Background(), 8)
+	require.ErrorIs(t, err, ErrConfigRejected)
+	assert.Contains(t, err.Error(), "error")
+}
+
+func TestSetServiceThreadsReportsUnappliedChange(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "service-threads=4", &commands)
+
+	err := c.SetServiceThreads(context.Background(), 8)
+	require.ErrorIs(t, err, ErrConfigRejected)
+	assert.Contains(t, err.Error(), `"4"`)
+}
+
+func TestSetMigrateThreads(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "migrate-threads=4;service-threads=8", &commands)
+
+	require.NoError(t, c.SetMigrateThreads(context.Background(), 4))
+	assert.Equal(t, "set-config:context=service;migrate-threads=4", commands[0])
+
+	require.ErrorIs(t, c.SetMigrateThreads(context.Background(), -1), ErrInvalidArgument)
+}
+
+func TestForceEviction(t *testing.T) {
+	var commands []string
+	c := Container{
+		Container: &fakeContainer{
+			exec: func(_ context.Context, cmd []string) (int, string, error) {
+				command := cmd[len(cmd)-1]
+				commands = append(commands, command)
+				switch {
+				case strings.HasPrefix(command, "set-config:"):
+					return 0, "ok", nil
+				case len(commands) < 3:
+					return 0, "nsup-period=120;default-ttl=0", nil
+				case len(commands) < 5:
+					return 0, "nsup-period=1;default-ttl=0", nil
+				default:
+					return 0, "nsup-period=120;default-ttl=0", nil
+				}
+			},
+		},
+		settings: defaultOptions(),
+	}
+
+	// The cycle is awaited while the period is lowered.
+	waited := false
+	waitForCycle := func(_ context.Context, namespace string, _ time.Time) error {
+		waited = true
+		assert.Equal(t, "test", namespace)
+		assert.Len(t, commands, 3)
+		return nil
+	}
+
+	require.NoError(t, c.forceEviction(context.Background(), "test", waitForCycle))
+	assert.True(t, waited)
+	assert.Equal(t, []string{
+		"get-config:context=namespace;id=test",
+		"set-config:context=namespace;id=test;nsup-period=1",
+		"get-config:context=namespace;id=test",
+		"set-config:context=namespace;id=test;nsup-period=120",
+		"get-config:context=namespace;id=test",
+	}, commands)
+}
+
+func TestForceEvictionRequiresNsup(t *testing.T) {
+	var commands []string
+	c := configContainer("ok", "nsup-period=0;default-ttl=0", &commands)
+
+	require.ErrorIs(t, c.ForceEviction(context.Background(), "test"), ErrEvictionNotApplicable)
+	assert.Len(t, commands, 1)
+}
+
+func TestSetServiceThreadsRuntime(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	require.NoError(t, container.SetServiceThreads(ctx, 3))
+	require.NoError(t, container.SetMigrateThreads(ctx, 2))
+}
+
+func TestSetConfigRuntime(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	require.NoError(t, container.SetConfig(ctx, "namespace", "test", "stop-writes-sys-memory-pct", "50"))
+
+	resp, err := container.AsInfo(ctx, "get-config:context=namespace;id=test")
+	require.NoError(t, err)
+	assert.Equal(t, "50", parseInfoPairs(resp, ";")["stop-writes-sys-memory-pct"])
+
+	err = container.SetConfig(ctx, "namespace", "test", "no-such-param", "1")
+	require.ErrorIs(t, err, ErrConfigRejected)
+}
+
+func TestForceEvictionRemovesExpiredRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithTTLSupport("test"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	key, err := aerospike.NewKey("test", "evict", "expiring")
+	require.NoError(t, err)
+	writePolicy := aerospike.NewWritePolicy(0, 1)
+	require.NoError(t, client.Put(writePolicy, key, aerospike.BinMap{"bin": "value"}))
+
+	time.Sleep(2 * time.Second)
+	require.NoError(t, container.ForceEviction(ctx, "test"))
+
+	// Expired records are hidden from reads right away; the supervisor
+	// accounts for them once it has deleted them.
+	stats, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	expired, err := statInt(stats, "expired_objects")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+}
@@ -0,0 +1,86 @@
+package aerospike
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+)
+
+// SchemaBuilder collects the UDF modules and secondary indexes a test needs
+// and creates them all with Apply, so a realistic schema can be declared in
+// one place:
+//
+//	err := aerospike.NewSchemaBuilder().
+//		Index("test", "users", "age", "users_age", aero.NUMERIC).
+//		Index("test", "users", "email", "users_email", aero.STRING).
+//		Index("test", "orders", "user", "orders_user", aero.STRING).
+//		UDF("counters", counters).
+//		Apply(ctx, container)
+//
+// Aerospike creates sets on their first write, so sets only appear in the
+// schema through the indexes defined on them. The zero value is an empty
+// schema ready to use.
+type SchemaBuilder struct {
+	udfs    []schemaUDF
+	indexes []schemaIndex
+}
+
+type schemaUDF struct {
+	moduleName string
+	luaSource  []byte
+}
+
+type schemaIndex struct {
+	namespace string
+	set       string
+	binName   string
+	indexName string
+	indexType aerospike.IndexType
+}
+
+// NewSchemaBuilder returns an empty SchemaBuilder.
+func NewSchemaBuilder() *SchemaBuilder {
+	return &SchemaBuilder{}
+}
+
+// UDF adds the Lua module moduleName to the schema, registered as with
+// Container.RegisterUDF.
+func (b *SchemaBuilder) UDF(moduleName string, luaSource []byte) *SchemaBuilder {
+	b.udfs = append(b.udfs, schemaUDF{moduleName: moduleName, luaSource: luaSource})
+	return b
+}
+
+// Index adds the secondary index indexName over binName in namespace.set to
+// the schema, created as with Container.CreateSecondaryIndex. An empty set
+// indexes the whole namespace.
+func (b *SchemaBuilder) Index(namespace, set, binName, indexName string, indexType aerospike.IndexType) *SchemaBuilder {
+	b.indexes = append(b.indexes, schemaIndex{
+		namespace: namespace,
+		set:       set,
+		binName:   binName,
+		indexName: indexName,
+		indexType: indexType,
+	})
+	return b
+}
+
+// Apply registers the UDF modules and then creates the secondary indexes of
+// the schema in c, in the order they were added, and returns once every index
+// has been built. Applying a schema again is not an error, as both steps
+// accept definitions that already exist. The first failure stops Apply and
+// names the module or index that failed.
+func (b *SchemaBuilder) Apply(ctx context.Context, c Container) error {
+	for _, udf := range b.udfs {
+		if err := c.RegisterUDF(ctx, udf.moduleName, udf.luaSource); err != nil {
+			return fmt.Errorf("failed to register UDF module %q: %w", udf.moduleName, err)
+		}
+	}
+	for _, index := range b.indexes {
+		if err := c.CreateSecondaryIndex(ctx, index.namespace, index.set, index.binName, index.indexName, index.indexType); err != nil {
+			return fmt.Errorf("failed to create index %q: %w", index.indexName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// twoSetSchema returns a schema of two sets with three indexes between them.
+func twoSetSchema() *SchemaBuilder {
+	return NewSchemaBuilder().
+		Index("test", "users", "age", "users_age", aerospike.NUMERIC).
+		Index("test", "users", "email", "users_email", aerospike.STRING).
+		Index("test", "orders", "user", "orders_user", aerospike.STRING)
+}
+
+func TestSchemaBuilderApply(t *testing.T) {
+	var commands []string
+	c := sindexContainer("OK",
+		"ns=test:indexname=users_age:set=users:bin=age:type=numeric:state=RW;"+
+			"ns=test:indexname=users_email:set=users:bin=email:type=string:state=RW;"+
+			"ns=test:indexname=orders_user:set=orders:bin=user:type=string:state=RW;",
+		&commands)
+
+	require.NoError(t, twoSetSchema().Apply(context.Background(), c))
+	assert.Equal(t, []string{
+		"sindex-create:namespace=test;set=users;indexname=users_age;bin=age;type=numeric",
+		"sindex-list:ns=test",
+		"sindex-create:namespace=test;set=users;indexname=users_email;bin=email;type=string",
+		"sindex-list:ns=test",
+		"sindex-create:namespace=test;set=orders;indexname=orders_user;bin=user;type=string",
+		"sindex-list:ns=test",
+	}, commands)
+}
+
+func TestSchemaBuilderApplyNamesFailedIndex(t *testing.T) {
+	var commands []string
+	c := sindexContainer("OK", "", &commands)
+
+	err := NewSchemaBuilder().
+		Index("test", "users", "", "users_age", aerospike.NUMERIC).
+		Apply(context.Background(), c)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Contains(t, err.Error(), `"users_age"`)
+
+	err = NewSchemaBuilder().UDF("", []byte("return 1")).Apply(context.Background(), c)
+	require.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Empty(t, commands)
+}
+
+func TestSchemaBuilderApplyCreatesIndexes(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	schema := twoSetSchema()
+	require.NoError(t, schema.Apply(ctx, *container))
+	// Applying the same schema again leaves it in place.
+	require.NoError(t, schema.Apply(ctx, *container))
+
+	for _, name := range []string{"users_age", "users_email", "orders_user"} {
+		index, found, err := container.secondaryIndex(ctx, "test", name)
+		require.NoError(t, err)
+		require.True(t, found, name)
+		assert.Equal(t, "RW", index["state"], name)
+	}
+}
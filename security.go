@@ -0,0 +1,296 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	// defaultAdminUser and defaultAdminPassword are the credentials of the
+	// user the server creates when security is first enabled.
+	defaultAdminUser     = "admin"
+	defaultAdminPassword = "admin"
+	// securityLoginTimeout bounds how long the first admin login may keep
+	// failing while the security subsystem starts.
+	securityLoginTimeout = 30 * time.Second
+)
+
+// adminRoles are the roles granted to the admin user created by WithSecurity,
+// which together allow everything a test may need.
+//
+//nolint:gochecknoglobals // treated as a constant
+var adminRoles = []string{
+	string(aerospike.UserAdmin),
+	string(aerospike.SysAdmin),
+	string(aerospike.DataAdmin),
+	string(aerospike.ReadWriteUDF),
+	string(aerospike.Truncate),
+}
+
+// WithSecurity enables access control and provisions adminUser with
+// adminPassword once the server is ready. The user is granted the user-admin,
+// sys-admin, data-admin, read-write-udf and truncate roles; passing "admin"
+// changes the password of the built-in admin user instead. NewClient, the
+// other Container helpers and asinfo then authenticate with these
+// credentials, which AdminUser and AdminPassword return.
+//
+// Security is an enterprise feature: it requires WithEnterpriseEdition and,
+// outside the single-node evaluation mode, a feature key that enables it
+// (see WithFeatureKeyFile).
+func WithSecurity(adminUser, adminPassword string) Option {
+	return func(o *options) error {
+		if adminUser == "" || adminPassword == "" {
+			return fmt.Errorf("%w: admin user and password must not be empty", ErrInvalidOption)
+		}
+		o.user = adminUser
+		o.password = adminPassword
+
+		o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				return fmt.Errorf("%w: security requires an enterprise image, got %q", ErrInvalidOption, req.Image)
+			}
+			cfg.root.child("security")
+
+			// Until the hook below has run once, only the built-in admin can
+			// log in; on later starts, such as with WithReuse, its password
+			// may have been changed.
+			if strategy, ok := req.WaitingFor.(aerospikeWaitStrategy); ok {
+				strategy.user = o.user
+				strategy.password = o.password
+				strategy.fallbackUser = defaultAdminUser
+				strategy.fallbackPassword = defaultAdminPassword
+				req.WaitingFor = strategy
+			}
+			// The hooks of the other options log in as the admin user, so it
+			// has to be provisioned first, whatever the order of the options.
+			req.LifecycleHooks = append([]testcontainers.ContainerLifecycleHooks{{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						// o holds every option by the time the hook runs.
+						return provisionAdmin(ctx, c, *o)
+					},
+				},
+			}}, req.LifecycleHooks...)
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WithRole creates the role name with privileges once security has been set
+// up, for tests that check what a limited user may do. Each privilege is a
+// privilege code, optionally scoped to a namespace and a set with dots, such
+// as "read", "read-write.test" or "write.test.users". Grant the role to users
+// with WithUser. It requires WithSecurity.
+func WithRole(name string, privileges ...string) Option {
+	return func(o *options) error {
+		if name = strings.TrimSpace(name); name == "" {
+			return fmt.Errorf("%w: role name is empty", ErrInvalidOption)
+		}
+		if len(privileges) == 0 {
+			return fmt.Errorf("%w: role %s has no privileges", ErrInvalidOption, name)
+		}
+
+		role := roleSpec{name: name}
+		for _, privilege := range privileges {
+			p, err := parsePrivilege(privilege)
+			if err != nil {
+				return fmt.Errorf("role %s: %w", name, err)
+			}
+			role.privileges = append(role.privileges, p)
+		}
+		o.roles = append(o.roles, role)
+
+		return nil
+	}
+}
+
+// WithUser creates the user username with password once security has been set
+// up, granted roles, which may be predefined roles such as "read" or roles
+// created with WithRole. Tests can then connect as the user with NewClientAs,
+// or read its password back with UserPassword, to check that operations
+// outside its roles are denied. It requires WithSecurity.
+func WithUser(username, password string, roles ...string) Option {
+	return func(o *options) error {
+		if username == "" || password == "" {
+			return fmt.Errorf("%w: user name and password must not be empty", ErrInvalidOption)
+		}
+		if username == defaultAdminUser || slices.ContainsFunc(o.users, func(u userSpec) bool { return u.name == username }) {
+			return fmt.Errorf("%w: user %s is already defined", ErrInvalidOption, username)
+		}
+		o.users = append(o.users, userSpec{name: username, password: password, roles: roles})
+
+		return nil
+	}
+}
+
+// AdminUser returns the admin user set with WithSecurity, or "" when security
+// is not enabled.
+func (c Container) AdminUser() string {
+	return c.settings.user
+}
+
+// AdminPassword returns the admin password set with WithSecurity, or "" when
+// security is not enabled.
+func (c Container) AdminPassword() string {
+	return c.settings.password
+}
+
+// UserPassword returns the password of a user created with WithUser, and
+// false when no such user was defined.
+func (c Container) UserPassword(username string) (string, bool) {
+	for _, u := range c.settings.users {
+		if u.name == username {
+			return u.password, true
+		}
+	}
+
+	return "", false
+}
+
+// NewClientAs is like NewClient but authenticates as username, a user created
+// with WithUser, instead of the admin user. The caller owns the client and
+// must Close it.
+func (c Container) NewClientAs(ctx context.Context, username string) (*aerospike.Client, error) {
+	password, ok := c.UserPassword(username)
+	if !ok {
+		return nil, fmt.Errorf("%w: user %s was not created with WithUser", ErrInvalidArgument, username)
+	}
+
+	settings := c.settings
+	settings.user = username
+	settings.password = password
+
+	return Container{Container: c.Container, settings: settings}.NewClient(ctx)
+}
+
+// provisionAdmin logs in as the built-in admin and creates the admin user set
+// with WithSecurity, or changes the built-in admin's password when that user
+// is the built-in admin, followed by the roles and users set with WithRole and
+// WithUser. The security subsystem may still be starting when the container
+// is reported ready, so the login is retried for up to securityLoginTimeout.
+//
+// A container started again, such as with WithReuse or after Stop, keeps what
+// an earlier start provisioned: the admin user's own credentials are tried
+// first, and users and roles that already exist are left alone.
+func provisionAdmin(ctx context.Context, c testcontainers.Container, settings options) error {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch host: %w", err)
+	}
+	port, err := c.MappedPort(ctx, settings.servicePortSpec())
+	if err != nil {
+		return fmt.Errorf("failed to fetch port: %w", err)
+	}
+
+	policy := aerospike.NewClientPolicy()
+	policy.Timeout = defaultClientTimeout
+	logins := [][2]string{{settings.user, settings.password}, {defaultAdminUser, defaultAdminPassword}}
+
+	var client *aerospike.Client
+	var loginErr error
+	err = pollUntil(ctx, securityLoginTimeout, func(context.Context) (bool, error) {
+		for _, login := range logins {
+			policy.User, policy.Password = login[0], login[1]
+			var aerr aerospike.Error
+			if client, aerr = aerospike.NewClientWithPolicy(policy, host, int(port.Num())); aerr == nil {
+				return true, nil
+			}
+			loginErr = aerr
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log in as %s or %s: %w", settings.user, defaultAdminUser, errors.Join(err, loginErr))
+	}
+	defer client.Close()
+
+	user, password := settings.user, settings.password
+	switch {
+	case policy.User == user && policy.Password == password:
+		// Provisioned by an earlier start.
+	case user != defaultAdminUser:
+		if aerr := client.CreateUser(nil, user, password, adminRoles); aerr != nil && !aerr.Matches(types.USER_ALREADY_EXISTS) {
+			return fmt.Errorf("failed to create user %s: %w", user, aerr)
+		}
+	default:
+		if aerr := client.ChangePassword(nil, user, password); aerr != nil {
+			return fmt.Errorf("failed to change the %s password: %w", user, aerr)
+		}
+	}
+
+	// Roles come first, as users are granted them.
+	for _, role := range settings.roles {
+		if aerr := client.CreateRole(nil, role.name, role.privileges, nil, 0, 0); aerr != nil && !aerr.Matches(types.ROLE_ALREADY_EXISTS) {
+			return fmt.Errorf("failed to create role %s: %w", role.name, aerr)
+		}
+	}
+	for _, u := range settings.users {
+		if aerr := client.CreateUser(nil, u.name, u.password, u.roles); aerr != nil && !aerr.Matches(types.USER_ALREADY_EXISTS) {
+			return fmt.Errorf("failed to create user %s with roles %v: %w", u.name, u.roles, aerr)
+		}
+	}
+
+	return nil
+}
+
+// roleSpec is a role set with WithRole.
+type roleSpec struct {
+	name       string
+	privileges []aerospike.Privilege
+}
+
+// userSpec is a user set with WithUser.
+type userSpec struct {
+	name     string
+	password string
+	roles    []string
+}
+
+// privileges lists every privilege code WithRole accepts.
+//
+//nolint:gochecknoglobals // treated as a constant
+var privileges = []aerospike.Privilege{
+	{Code: aerospike.UserAdmin},
+	{Code: aerospike.SysAdmin},
+	{Code: aerospike.DataAdmin},
+	{Code: aerospike.UDFAdmin},
+	{Code: aerospike.SIndexAdmin},
+	{Code: aerospike.ReadWriteUDF},
+	{Code: aerospike.ReadWrite},
+	{Code: aerospike.Read},
+	{Code: aerospike.Write},
+	{Code: aerospike.Truncate},
+}
+
+// parsePrivilege parses a privilege in the form used by WithRole, such as
+// "read-write.test.users".
+func parsePrivilege(s string) (aerospike.Privilege, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ".", 3)
+	for _, p := range privileges {
+		if string(p.Code) != parts[0] {
+			continue
+		}
+		if len(parts) > 1 {
+			p.Namespace = parts[1]
+		}
+		if len(parts) > 2 {
+			p.SetName = parts[2]
+		}
+		if (len(parts) > 1 && p.Namespace == "") || (len(parts) > 2 && p.SetName == "") {
+			return aerospike.Privilege{}, fmt.Errorf("%w: privilege %q has an empty scope", ErrInvalidOption, s)
+		}
+		return p, nil
+	}
+
+	return aerospike.Privilege{}, fmt.Errorf("%w: unknown privilege %q", ErrInvalidOption, s)
+}
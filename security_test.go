@@ -0,0 +1,228 @@
+package aerospike
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSecurity(t *testing.T) {
+	req, settings, err := newContainerRequest(WithEnterpriseEdition(), WithSecurity("tester", "secret"))
+	require.NoError(t, err)
+
+	c := Container{settings: settings}
+	assert.Equal(t, "tester", c.AdminUser())
+	assert.Equal(t, "secret", c.AdminPassword())
+
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Equal(t, "tester", strategy.user)
+	assert.Equal(t, "secret", strategy.password)
+	assert.Equal(t, defaultAdminUser, strategy.fallbackUser)
+	assert.Equal(t, defaultAdminPassword, strategy.fallbackPassword)
+
+	require.Len(t, req.LifecycleHooks, 1)
+	assert.Len(t, req.LifecycleHooks[0].PostReadies, 1)
+
+	conf := renderedConfig(t, WithEnterpriseEdition(), WithSecurity("tester", "secret"))
+	assert.Contains(t, conf, "\nsecurity {\n}\n")
+}
+
+func TestWithSecurityValidation(t *testing.T) {
+	_, _, err := newContainerRequest(WithEnterpriseEdition(), WithSecurity("", "secret"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithSecurity("tester", ""))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithSecurity("tester", "secret"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestAsInfoAuthenticatesWithSecurity(t *testing.T) {
+	settings := defaultOptions()
+	require.NoError(t, WithSecurity("tester", "secret")(&settings))
+
+	var gotCmd []string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			gotCmd = cmd
+			return 0, "8.0.0.1", nil
+		}},
+		settings: settings,
+	}
+
+	_, err := c.AsInfo(context.Background(), "build")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asinfo", "-U", "tester", "-P", "secret", "-v", "build"}, gotCmd)
+}
+
+func TestDescribeCommandMasksPassword(t *testing.T) {
+	assert.Equal(t, `"asinfo -U tester -P *** -v build"`, describeCommand([]string{"asinfo", "-U", "tester", "-P", "secret", "-v", "build"}))
+	assert.Equal(t, `"asbackup --user tester --password=***"`, describeCommand([]string{"asbackup", "--user", "tester", "--password=secret"}))
+}
+
+func TestAsbenchAuthenticates(t *testing.T) {
+	settings := defaultOptions()
+	settings.user, settings.password = "tester", "secret"
+
+	var gotCmd []string
+	c := Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			if cmd[0] != "asbench" {
+				return 0, "", nil
+			}
+			gotCmd = cmd
+			return 0, asbenchOutputSample, nil
+		}},
+		settings: settings,
+	}
+
+	_, err := c.Asbench(context.Background(), []string{"-d", "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"asbench", "-h", "127.0.0.1", "-p", "3000", "--user", "tester", "--password=secret", "-d", "1"}, gotCmd)
+}
+
+func TestWithSecuritySurvivesRestart(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithSecurity("admin", "secret"), WithUser("reader", "secret", "read"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	// admin/admin no longer works and the users exist, so the second start
+	// has to log in with the configured password and keep the users.
+	timeout := 10 * time.Second
+	require.NoError(t, container.Stop(ctx, &timeout))
+	require.NoError(t, container.Start(ctx))
+
+	client, err := container.NewClientAs(ctx, "reader")
+	require.NoError(t, err)
+	client.Close()
+}
+
+func TestWithSecurityAuthenticatesClients(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithSecurity("tester", "secret"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "secure", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	_, err = container.ListNamespaces(ctx)
+	require.NoError(t, err)
+
+	// Clients without credentials are turned away.
+	host, port, err := container.HostPort(ctx)
+	require.NoError(t, err)
+	_, aerr := aerospike.NewClient(host, port)
+	require.Error(t, aerr)
+}
+
+func TestWithRoleAndUser(t *testing.T) {
+	_, settings, err := newContainerRequest(
+		WithEnterpriseEdition(),
+		WithSecurity("tester", "secret"),
+		WithRole("reader", "read.test", "read-write.test.scratch", "sys-admin"),
+		WithUser("limited", "pass", "reader"),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, settings.roles, 1)
+	assert.Equal(t, []aerospike.Privilege{
+		{Code: aerospike.Read, Namespace: "test"},
+		{Code: aerospike.ReadWrite, Namespace: "test", SetName: "scratch"},
+		{Code: aerospike.SysAdmin},
+	}, settings.roles[0].privileges)
+
+	c := Container{settings: settings}
+	password, ok := c.UserPassword("limited")
+	assert.True(t, ok)
+	assert.Equal(t, "pass", password)
+	_, ok = c.UserPassword("tester")
+	assert.False(t, ok)
+
+	_, err = c.NewClientAs(context.Background(), "nobody")
+	require.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestWithRoleAndUserValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "empty role name", opt: WithRole(" ", "read")},
+		{name: "no privileges", opt: WithRole("reader")},
+		{name: "unknown privilege", opt: WithRole("reader", "read-only")},
+		{name: "empty privilege scope", opt: WithRole("reader", "read.")},
+		{name: "empty user name", opt: WithUser("", "pass")},
+		{name: "empty password", opt: WithUser("limited", "")},
+		{name: "built-in admin", opt: WithUser("admin", "pass")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newContainerRequest(WithEnterpriseEdition(), WithSecurity("tester", "secret"), tt.opt)
+			require.ErrorIs(t, err, ErrInvalidOption)
+		})
+	}
+
+	_, _, err := newContainerRequest(WithEnterpriseEdition(), WithSecurity("tester", "secret"), WithUser("limited", "a"), WithUser("limited", "b"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithUser("limited", "pass", "read"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "WithSecurity")
+}
+
+func TestWithUserIsDeniedOutsideItsRoles(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t,
+		WithEnterpriseEdition(),
+		WithSecurity("tester", "secret"),
+		WithRole("reader", "read.test"),
+		WithUser("limited", "pass", "reader"),
+	)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	admin, err := container.Client(ctx)
+	require.NoError(t, err)
+	key, err := aerospike.NewKey("test", "rbac", "key")
+	require.NoError(t, err)
+	require.NoError(t, admin.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	limited, err := container.NewClientAs(ctx, "limited")
+	require.NoError(t, err)
+	t.Cleanup(limited.Close)
+
+	record, aerr := limited.Get(nil, key)
+	require.NoError(t, aerr)
+	assert.Equal(t, "value", record.Bins["bin"])
+
+	aerr = limited.Put(nil, key, aerospike.BinMap{"bin": "changed"})
+	require.Error(t, aerr)
+	assert.True(t, aerr.Matches(types.ROLE_VIOLATION), aerr.Error())
+}
@@ -0,0 +1,292 @@
+package aerospike
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/bsv-blockchain/aerospike-client-go/v8/types"
+)
+
+// seedBatchSize is the number of records Seed writes per batch.
+const seedBatchSize = 500
+
+var (
+	// ErrRecordRejected is reported by Seed for a record the server refused
+	// without a more specific error.
+	ErrRecordRejected = errors.New("record rejected")
+	// ErrInvalidFixture is returned by SeedFromJSON when the fixture cannot be
+	// decoded.
+	ErrInvalidFixture = errors.New("invalid fixture")
+)
+
+// SeedRecord is one record written by Seed. Key is the user key, which must be
+// a type aerospike.NewKey accepts, such as a string, an integer or a []byte.
+type SeedRecord struct {
+	Key  any
+	Bins aerospike.BinMap
+}
+
+// SeedOption changes how Seed handles failed records.
+type SeedOption func(*seedSettings)
+
+type seedSettings struct {
+	continueOnError bool
+}
+
+// ContinueOnError makes Seed write every record even after some fail, instead
+// of stopping at the batch that holds the first failure. The returned
+// *SeedError then lists every failure.
+func ContinueOnError() SeedOption {
+	return func(s *seedSettings) {
+		s.continueOnError = true
+	}
+}
+
+// SeedError is returned by Seed when records could not be written. It reports
+// how many were written and wraps the error of each record that failed.
+type SeedError struct {
+	// Written is the number of records written successfully.
+	Written int
+	// Total is the number of records passed to Seed.
+	Total int
+	// Errs holds one error per failed record, naming its index and key.
+	Errs []error
+}
+
+// Error implements the error interface.
+func (e *SeedError) Error() string {
+	return fmt.Sprintf("seeded %d of %d records, %d failed: %v", e.Written, e.Total, len(e.Errs), e.Errs[0])
+}
+
+// Unwrap returns the errors of the failed records, so errors.Is and errors.As
+// can match them.
+func (e *SeedError) Unwrap() []error {
+	return e.Errs
+}
+
+// Seed writes records to namespace.set in batches of 500, replacing any
+// existing record with the same key. It stops after the batch holding the
+// first failed record unless ContinueOnError is passed. When records fail, the
+// returned error is a *SeedError that reports how many were written.
+func (c Container) Seed(ctx context.Context, namespace, set string, records []SeedRecord, opts ...SeedOption) error {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+	set = strings.TrimSpace(set)
+
+	var settings seedSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy := aerospike.NewBatchPolicy()
+	if err := applyDeadline(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+
+	result := &SeedError{Total: len(records)}
+	for start := 0; start < len(records); start += seedBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunk := records[start:min(start+seedBatchSize, len(records))]
+		batch := make([]aerospike.BatchRecordIfc, 0, len(chunk))
+		indexes := make([]int, 0, len(chunk))
+		for i, record := range chunk {
+			key, aerr := aerospike.NewKey(namespace, set, record.Key)
+			if aerr != nil {
+				result.Errs = append(result.Errs, fmt.Errorf("record %d: invalid key %v: %w", start+i, record.Key, aerr))
+				continue
+			}
+			ops := make([]*aerospike.Operation, 0, len(record.Bins))
+			for name, value := range record.Bins {
+				ops = append(ops, aerospike.PutOp(aerospike.NewBin(name, value)))
+			}
+			batch = append(batch, aerospike.NewBatchWrite(nil, key, ops...))
+			indexes = append(indexes, start+i)
+		}
+
+		if len(batch) > 0 {
+			if aerr := client.BatchOperate(policy, batch); aerr != nil {
+				return fmt.Errorf("failed to seed records %d to %d: %w", start, start+len(chunk)-1, aerr)
+			}
+		}
+		for i, record := range batch {
+			rec := record.BatchRec()
+			if rec.ResultCode == types.OK {
+				result.Written++
+				continue
+			}
+			if rec.Err == nil {
+				result.Errs = append(result.Errs, fmt.Errorf("record %d (key %v): %w: %s", indexes[i], rec.Key.Value(), ErrRecordRejected, rec.ResultCode))
+				continue
+			}
+			result.Errs = append(result.Errs, fmt.Errorf("record %d (key %v): %w", indexes[i], rec.Key.Value(), rec.Err))
+		}
+
+		if len(result.Errs) > 0 && !settings.continueOnError {
+			break
+		}
+	}
+
+	if len(result.Errs) > 0 {
+		return result
+	}
+
+	return nil
+}
+
+// SeedFromJSON decodes a JSON array of records from r and writes them to
+// namespace.set with Seed. Each record is an object such as
+//
+//	{"key": "user-1", "bins": {"name": "Ann", "age": 31, "tags": ["a", "b"]}}
+//
+// where the key is a string or an integer. Bin values may be strings, integers,
+// floats, booleans, or nested objects and arrays, which are stored as maps and
+// lists. Numbers without a fraction or exponent are stored as integers.
+//
+// The whole fixture is decoded before anything is written; a record that fails
+// to decode is reported with its index and line as ErrInvalidFixture.
+func (c Container) SeedFromJSON(ctx context.Context, namespace, set string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	records, err := decodeFixture(data)
+	if err != nil {
+		return err
+	}
+
+	return c.Seed(ctx, namespace, set, records)
+}
+
+// decodeFixture decodes the JSON array of records accepted by SeedFromJSON.
+func decodeFixture(data []byte) ([]SeedRecord, error) {
+	// line returns the line of the first value at or after offset, skipping
+	// the whitespace and separators the decoder has not consumed yet.
+	line := func(offset int64) int {
+		rest := bytes.TrimLeft(data[offset:], " \t\r\n,")
+		return bytes.Count(data[:len(data)-len(rest)], []byte("\n")) + 1
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fmt.Errorf("%w: expected a JSON array of records", ErrInvalidFixture)
+	}
+
+	var records []SeedRecord
+	for i := 0; dec.More(); i++ {
+		start := line(dec.InputOffset())
+
+		var raw struct {
+			Key  any            `json:"key"`
+			Bins map[string]any `json:"bins"`
+		}
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("%w: record %d (line %d): %w", ErrInvalidFixture, i, start, err)
+		}
+
+		record, err := fixtureRecord(raw.Key, raw.Bins)
+		if err != nil {
+			return nil, fmt.Errorf("record %d (line %d): %w", i, start, err)
+		}
+		records = append(records, record)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidFixture, line(dec.InputOffset()), err)
+	}
+
+	return records, nil
+}
+
+// fixtureRecord converts a decoded fixture record into a SeedRecord.
+func fixtureRecord(key any, bins map[string]any) (SeedRecord, error) {
+	record := SeedRecord{Bins: make(aerospike.BinMap, len(bins))}
+
+	switch k := key.(type) {
+	case string:
+		record.Key = k
+	case json.Number:
+		n, err := k.Int64()
+		if err != nil {
+			return record, fmt.Errorf("%w: key %s is not an integer", ErrInvalidFixture, k)
+		}
+		record.Key = n
+	case nil:
+		return record, fmt.Errorf("%w: key is missing", ErrInvalidFixture)
+	default:
+		return record, fmt.Errorf("%w: key must be a string or an integer, got %T", ErrInvalidFixture, key)
+	}
+
+	for name, value := range bins {
+		v, err := fixtureValue(value)
+		if err != nil {
+			return record, fmt.Errorf("bin %q: %w", name, err)
+		}
+		record.Bins[name] = v
+	}
+
+	return record, nil
+}
+
+// fixtureValue converts a decoded JSON value into a bin value, mapping objects
+// and arrays to Aerospike maps and lists.
+func fixtureValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %s", ErrInvalidFixture, v)
+		}
+		return f, nil
+	case string, bool:
+		return v, nil
+	case []any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			converted, err := fixtureValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			list[i] = converted
+		}
+		return list, nil
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			converted, err := fixtureValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			m[key] = converted
+		}
+		return m, nil
+	case nil:
+		return nil, fmt.Errorf("%w: null values are not supported", ErrInvalidFixture)
+	default:
+		return nil, fmt.Errorf("%w: unsupported value of type %T", ErrInvalidFixture, value)
+	}
+}
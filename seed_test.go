@@ -0,0 +1,163 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedError(t *testing.T) {
+	errFirst := errors.New("first")
+	err := &SeedError{Written: 8, Total: 10, Errs: []error{errFirst, errors.New("second")}}
+
+	assert.Equal(t, "seeded 8 of 10 records, 2 failed: first", err.Error())
+	require.ErrorIs(t, err, errFirst)
+}
+
+func TestSeedValidatesArguments(t *testing.T) {
+	var c Container
+
+	require.ErrorIs(t, c.Seed(context.Background(), " ", "set", []SeedRecord{{Key: 1}}), ErrInvalidArgument)
+	require.NoError(t, c.Seed(context.Background(), "test", "set", nil))
+}
+
+func TestSeed(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	records := make([]SeedRecord, 1000)
+	for i := range records {
+		records[i] = SeedRecord{Key: i, Bins: aerospike.BinMap{"n": i, "name": "record"}}
+	}
+	require.NoError(t, container.Seed(ctx, "test", "seed", records))
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	for _, i := range []int{0, 499, 500, 999} {
+		key, err := aerospike.NewKey("test", "seed", i)
+		require.NoError(t, err)
+		record, err := client.Get(nil, key)
+		require.NoError(t, err)
+		assert.Equal(t, i, record.Bins["n"])
+	}
+
+	count, err := container.QueryCount(ctx, "test", "seed", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, count)
+}
+
+func TestSeedReportsFailures(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	records := make([]SeedRecord, 1200)
+	for i := range records {
+		records[i] = SeedRecord{Key: i, Bins: aerospike.BinMap{"n": i}}
+	}
+	// Bin names are limited to 15 characters.
+	records[10].Bins = aerospike.BinMap{"a-bin-name-that-is-too-long": 1}
+
+	err := container.Seed(ctx, "test", "failfast", records)
+	var seedErr *SeedError
+	require.ErrorAs(t, err, &seedErr)
+	assert.Equal(t, 499, seedErr.Written)
+	assert.Len(t, seedErr.Errs, 1)
+
+	err = container.Seed(ctx, "test", "continue", records, ContinueOnError())
+	require.ErrorAs(t, err, &seedErr)
+	assert.Equal(t, 1199, seedErr.Written)
+	assert.Len(t, seedErr.Errs, 1)
+}
+
+func TestDecodeFixture(t *testing.T) {
+	records, err := decodeFixture([]byte(`[
+		{"key": "user-1", "bins": {"name": "Ann", "age": 31, "score": 9.5, "active": true}},
+		{"key": 2, "bins": {"tags": ["a", 1], "address": {"city": "Leeds", "zip": {"code": 12}}}}
+	]`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []SeedRecord{
+		{Key: "user-1", Bins: aerospike.BinMap{"name": "Ann", "age": int64(31), "score": 9.5, "active": true}},
+		{Key: int64(2), Bins: aerospike.BinMap{
+			"tags":    []any{"a", int64(1)},
+			"address": map[string]any{"city": "Leeds", "zip": map[string]any{"code": int64(12)}},
+		}},
+	}, records)
+}
+
+func TestDecodeFixtureReportsRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    string
+	}{
+		{name: "not an array", fixture: `{"key": 1}`, want: "expected a JSON array"},
+		{name: "missing key", fixture: "[\n{\"key\": 1, \"bins\": {}},\n{\"bins\": {}}\n]", want: "record 1 (line 3): invalid fixture: key is missing"},
+		{name: "float key", fixture: `[{"key": 1.5, "bins": {}}]`, want: "record 0 (line 1): invalid fixture: key 1.5 is not an integer"},
+		{name: "null bin", fixture: `[{"key": 1, "bins": {"tags": [null]}}]`, want: `record 0 (line 1): bin "tags": index 0: invalid fixture: null values are not supported`},
+		{name: "malformed record", fixture: "[\n{\"key\": 1, \"bins\": 3}\n]", want: "record 0 (line 2)"},
+		{name: "unterminated array", fixture: `[{"key": 1, "bins": {}}`, want: "invalid fixture"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeFixture([]byte(tt.fixture))
+			require.ErrorIs(t, err, ErrInvalidFixture)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestSeedFromJSON(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	fixture := `[
+		{"key": "user-1", "bins": {"name": "Ann", "age": 31, "score": 9.5}},
+		{"key": "user-2", "bins": {"tags": ["a", "b"], "address": {"city": "Leeds"}}}
+	]`
+	require.NoError(t, container.SeedFromJSON(ctx, "test", "fixtures", strings.NewReader(fixture)))
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "fixtures", "user-1")
+	require.NoError(t, err)
+	record, err := client.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, 31, record.Bins["age"])
+	assert.InDelta(t, 9.5, record.Bins["score"], 0)
+
+	key, err = aerospike.NewKey("test", "fixtures", "user-2")
+	require.NoError(t, err)
+	record, err = client.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"a", "b"}, record.Bins["tags"])
+	assert.Equal(t, map[any]any{"city": "Leeds"}, record.Bins["address"])
+}
@@ -0,0 +1,114 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+)
+
+// sindexBuildTimeout bounds how long CreateSecondaryIndex waits for an index
+// build to complete.
+const sindexBuildTimeout = time.Minute
+
+// ErrIndexConflict is returned by CreateSecondaryIndex when an index with the
+// same name already exists with different parameters.
+var ErrIndexConflict = errors.New("secondary index conflict")
+
+// CreateSecondaryIndex creates the secondary index indexName over binName in
+// namespace.set and waits until the server has finished building it, so
+// queries against it see every existing record. An empty set indexes the
+// whole namespace. Creating an index that already exists with the same
+// parameters is not an error; ErrIndexConflict is returned when the name is
+// taken by an index with different parameters.
+func (c Container) CreateSecondaryIndex(ctx context.Context, namespace, set, binName, indexName string, indexType aerospike.IndexType) error {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+	set = strings.TrimSpace(set)
+	binName = strings.TrimSpace(binName)
+	indexName = strings.TrimSpace(indexName)
+	if binName == "" {
+		return fmt.Errorf("%w: bin name must not be empty", ErrInvalidArgument)
+	}
+	if indexName == "" || strings.ContainsAny(indexName, ";:=") {
+		return fmt.Errorf("%w: invalid index name %q", ErrInvalidArgument, indexName)
+	}
+	switch indexType {
+	case aerospike.NUMERIC, aerospike.STRING, aerospike.BLOB, aerospike.GEO2DSPHERE:
+	default:
+		return fmt.Errorf("%w: unsupported index type %q", ErrInvalidArgument, indexType)
+	}
+
+	want := map[string]string{
+		"set":  set,
+		"bin":  binName,
+		"type": strings.ToLower(string(indexType)),
+	}
+	if want["set"] == "" {
+		want["set"] = "NULL"
+	}
+
+	command := "sindex-create:namespace=" + namespace
+	if set != "" {
+		command += ";set=" + set
+	}
+	command += ";indexname=" + indexName + ";bin=" + binName + ";type=" + want["type"]
+
+	resp, err := c.AsInfo(ctx, command)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(resp, "ok") {
+		// The server refuses to create an index whose name is taken, so look
+		// for an identical index before reporting the failure.
+		existing, found, err := c.secondaryIndex(ctx, namespace, indexName)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: %s: %s", ErrUnexpectedInfoResponse, command, resp)
+		}
+		for param, value := range want {
+			if existing[param] != value {
+				return fmt.Errorf("%w: index %q already exists with %s=%s, want %s", ErrIndexConflict, indexName, param, existing[param], value)
+			}
+		}
+	}
+
+	err = pollUntil(ctx, sindexBuildTimeout, func(ctx context.Context) (bool, error) {
+		index, found, err := c.secondaryIndex(ctx, namespace, indexName)
+		if err != nil {
+			return false, err
+		}
+		return found && index["state"] == "RW", nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for index %q to be built", err, indexName)
+	}
+
+	return err
+}
+
+// secondaryIndex returns the sindex-list entry for indexName in namespace,
+// such as "ns=test:indexname=idx:set=demo:bin=b:type=numeric:state=RW", as
+// parsed pairs.
+func (c Container) secondaryIndex(ctx context.Context, namespace, indexName string) (map[string]string, bool, error) {
+	resp, err := c.AsInfo(ctx, "sindex-list:ns="+namespace)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, entry := range strings.Split(resp, ";") {
+		index := parseInfoPairs(entry, ":")
+		if index["indexname"] == indexName {
+			return index, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
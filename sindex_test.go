@@ -0,0 +1,102 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sindexContainer returns a Container that answers sindex-create with
+// createResp and sindex-list with listResp, recording every command.
+func sindexContainer(createResp, listResp string, commands *[]string) Container {
+	return Container{
+		Container: &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+			command := cmd[len(cmd)-1]
+			*commands = append(*commands, command)
+			if strings.HasPrefix(command, "sindex-create:") {
+				return 0, createResp, nil
+			}
+			return 0, listResp, nil
+		}},
+		settings: defaultOptions(),
+	}
+}
+
+func TestCreateSecondaryIndex(t *testing.T) {
+	var commands []string
+	c := sindexContainer("OK", "ns=test:indexname=by_n:set=demo:bin=n:type=numeric:indextype=default:context=NULL:state=RW;", &commands)
+
+	require.NoError(t, c.CreateSecondaryIndex(context.Background(), "test", "demo", "n", "by_n", aerospike.NUMERIC))
+	assert.Equal(t, []string{
+		"sindex-create:namespace=test;set=demo;indexname=by_n;bin=n;type=numeric",
+		"sindex-list:ns=test",
+	}, commands)
+}
+
+func TestCreateSecondaryIndexIsIdempotent(t *testing.T) {
+	var commands []string
+	c := sindexContainer("FAIL:200:index already exists", "ns=test:indexname=by_name:set=NULL:bin=name:type=string:state=RW;", &commands)
+
+	require.NoError(t, c.CreateSecondaryIndex(context.Background(), "test", "", "name", "by_name", aerospike.STRING))
+	assert.Equal(t, "sindex-create:namespace=test;indexname=by_name;bin=name;type=string", commands[0])
+}
+
+func TestCreateSecondaryIndexReportsConflict(t *testing.T) {
+	var commands []string
+	c := sindexContainer("FAIL:200:index already exists", "ns=test:indexname=by_n:set=demo:bin=n:type=string:state=RW;", &commands)
+
+	err := c.CreateSecondaryIndex(context.Background(), "test", "demo", "n", "by_n", aerospike.NUMERIC)
+	require.ErrorIs(t, err, ErrIndexConflict)
+	assert.Contains(t, err.Error(), "type=string")
+}
+
+func TestCreateSecondaryIndexValidatesArguments(t *testing.T) {
+	var commands []string
+	c := sindexContainer("OK", "", &commands)
+
+	require.ErrorIs(t, c.CreateSecondaryIndex(context.Background(), "test", "demo", "", "by_n", aerospike.NUMERIC), ErrInvalidArgument)
+	require.ErrorIs(t, c.CreateSecondaryIndex(context.Background(), "test", "demo", "n", "by;n", aerospike.NUMERIC), ErrInvalidArgument)
+	require.ErrorIs(t, c.CreateSecondaryIndex(context.Background(), "test", "demo", "n", "by_n", aerospike.IndexType("LIST")), ErrInvalidArgument)
+	assert.Empty(t, commands)
+}
+
+func TestCreateSecondaryIndexQuery(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	for i := range 20 {
+		key, err := aerospike.NewKey("test", "sindex", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"n": i}))
+	}
+
+	require.NoError(t, container.CreateSecondaryIndex(ctx, "test", "sindex", "n", "sindex_n", aerospike.NUMERIC))
+	require.NoError(t, container.CreateSecondaryIndex(ctx, "test", "sindex", "n", "sindex_n", aerospike.NUMERIC))
+
+	stmt := aerospike.NewStatement("test", "sindex")
+	require.NoError(t, stmt.SetFilter(aerospike.NewRangeFilter("n", 5, 9)))
+
+	recordset, err := client.Query(nil, stmt)
+	require.NoError(t, err)
+
+	var found []int
+	for result := range recordset.Results() {
+		require.NoError(t, result.Err)
+		found = append(found, result.Record.Bins["n"].(int))
+	}
+	assert.ElementsMatch(t, []int{5, 6, 7, 8, 9}, found)
+}
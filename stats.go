@@ -0,0 +1,149 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrStatNotFound is returned when the server does not report a statistic,
+// usually because its name differs in the running server version.
+var ErrStatNotFound = errors.New("statistic not reported")
+
+// MemoryBreakdown is the memory a namespace uses, split by what it holds.
+type MemoryBreakdown struct {
+	IndexBytes          int64
+	SecondaryIndexBytes int64
+	DataBytes           int64
+}
+
+// MemoryBreakdown returns how much memory namespace uses for its primary
+// index, secondary indexes and data. Server 7.0 renamed these statistics, so
+// both the current and the pre-7.0 names are accepted.
+func (c Container) MemoryBreakdown(ctx context.Context, namespace string) (MemoryBreakdown, error) {
+	stats, err := c.NamespaceStats(ctx, namespace)
+	if err != nil {
+		return MemoryBreakdown{}, err
+	}
+
+	var breakdown MemoryBreakdown
+	fields := []struct {
+		dst   *int64
+		names []string
+	}{
+		{&breakdown.IndexBytes, []string{"index_used_bytes", "memory_used_index_bytes"}},
+		{&breakdown.SecondaryIndexBytes, []string{"sindex_used_bytes", "memory_used_sindex_bytes"}},
+		{&breakdown.DataBytes, []string{"data_used_bytes", "memory_used_data_bytes"}},
+	}
+	for _, f := range fields {
+		n, err := statInt(stats, f.names...)
+		if err != nil {
+			return MemoryBreakdown{}, fmt.Errorf("namespace %q: %w", namespace, err)
+		}
+		*f.dst = n
+	}
+
+	return breakdown, nil
+}
+
+// CompressionRatio returns the average size of the records namespace stores
+// after compression, relative to their uncompressed size: 1 means the records
+// did not compress at all and 0.25 means they take a quarter of the space.
+// Server 7.0 renamed the statistic, so both the current and the pre-7.0 names
+// are accepted.
+func (c Container) CompressionRatio(ctx context.Context, namespace string) (float64, error) {
+	stats, err := c.NamespaceStats(ctx, namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, name := range []string{"data_compression_ratio", "device_compression_ratio", "pmem_compression_ratio"} {
+		value, ok := stats[name]
+		if !ok {
+			continue
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s=%q is not a number", ErrUnexpectedInfoResponse, name, value)
+		}
+		return ratio, nil
+	}
+
+	return 0, fmt.Errorf("namespace %q: %w: data_compression_ratio or device_compression_ratio", namespace, ErrStatNotFound)
+}
+
+// Stats returns the node's service statistics, the "statistics" info
+// response, keyed by name, so tests can assert on counters such as
+// client_write_success. Values are returned as reported; a statistic reported
+// without a value maps to the empty string.
+func (c Container) Stats(ctx context.Context) (map[string]string, error) {
+	resp, err := c.AsInfoNonEmpty(ctx, "statistics")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInfoPairs(resp, ";"), nil
+}
+
+// NamespaceStats returns the statistics and configuration of namespace, the
+// "namespace/<ns>" info response, keyed by name. It returns
+// ErrUnexpectedInfoResponse when the server does not know the namespace.
+func (c Container) NamespaceStats(ctx context.Context, namespace string) (map[string]string, error) {
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.AsInfoNonEmpty(ctx, "namespace/"+namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !isNamespaceInfo(resp) {
+		return nil, fmt.Errorf("%w: namespace %q: %s", ErrUnexpectedInfoResponse, namespace, resp)
+	}
+
+	return parseInfoPairs(resp, ";"), nil
+}
+
+// isNamespaceInfo reports whether resp is a "namespace/<ns>" response for a
+// namespace the server knows about.
+func isNamespaceInfo(resp string) bool {
+	return resp != "" && !strings.HasPrefix(resp, "type=unknown") && !strings.HasPrefix(resp, "ERROR")
+}
+
+// parseInfoPairs parses an info response made of key=value pairs separated by
+// sep. Only the first '=' separates key from value, so values may themselves
+// contain '='. Fields without '=' are kept with an empty value.
+func parseInfoPairs(resp, sep string) map[string]string {
+	pairs := make(map[string]string)
+	for _, field := range strings.Split(resp, sep) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(field, "=")
+		pairs[key] = value
+	}
+
+	return pairs
+}
+
+// statInt returns the first of names present in stats as an integer.
+func statInt(stats map[string]string, names ...string) (int64, error) {
+	for _, name := range names {
+		value, ok := stats[name]
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s=%q is not an integer", ErrUnexpectedInfoResponse, name, value)
+		}
+		return n, nil
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrStatNotFound, strings.Join(names, " or "))
+}
@@ -0,0 +1,131 @@
+package aerospike
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInfoPairs(t *testing.T) {
+	pairs := parseInfoPairs("objects=10;expr=a=b;;flag;empty=", ";")
+
+	assert.Equal(t, map[string]string{
+		"objects": "10",
+		"expr":    "a=b",
+		"flag":    "",
+		"empty":   "",
+	}, pairs)
+}
+
+func TestStatInt(t *testing.T) {
+	stats := map[string]string{"memory_used_index_bytes": "128", "bad": "x"}
+
+	n, err := statInt(stats, "index_used_bytes", "memory_used_index_bytes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(128), n)
+
+	_, err = statInt(stats, "missing")
+	require.ErrorIs(t, err, ErrStatNotFound)
+
+	_, err = statInt(stats, "bad")
+	require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+}
+
+func TestCompressionRatio(t *testing.T) {
+	ratio, err := statsContainer("objects=10;data_compression_ratio=0.125").CompressionRatio(context.Background(), "test")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.125, ratio, 1e-9)
+
+	ratio, err = statsContainer("objects=10;device_compression_ratio=1.000").CompressionRatio(context.Background(), "test")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, ratio, 1e-9)
+
+	_, err = statsContainer("objects=10").CompressionRatio(context.Background(), "test")
+	require.ErrorIs(t, err, ErrStatNotFound)
+}
+
+func TestStats(t *testing.T) {
+	stats, err := statsContainer("cluster_size=1;;client_write_success=3;tls_name=;query_filter=bin=x\n").Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"cluster_size":         "1",
+		"client_write_success": "3",
+		"tls_name":             "",
+		"query_filter":         "bin=x",
+	}, stats)
+}
+
+func TestNamespaceStats(t *testing.T) {
+	stats, err := statsContainer("objects=10;stop_writes=false").NamespaceStats(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, "10", stats["objects"])
+
+	_, err = statsContainer("type=unknown").NamespaceStats(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+}
+
+func TestStatsCountWrites(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.Client(ctx)
+	require.NoError(t, err)
+
+	before, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	writes, err := statInt(before, "client_write_success")
+	require.NoError(t, err)
+
+	key, err := aerospike.NewKey("test", "stats", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"n": 1}))
+
+	after, err := container.NamespaceStats(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(writes+1, 10), after["client_write_success"])
+
+	stats, err := container.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", stats["cluster_size"])
+}
+
+func TestMemoryBreakdown(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	host, err := container.Host(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike host")
+	port, err := container.ServicePort(ctx)
+	require.NoErrorf(t, err, "failed to fetch Aerospike port")
+
+	client := newAerospikeClient(t, host, port)
+
+	before, err := container.MemoryBreakdown(ctx, "test")
+	require.NoError(t, err)
+
+	for i := range 100 {
+		key, err := aerospike.NewKey("test", "memory", i)
+		require.NoError(t, err)
+		require.NoError(t, client.Put(nil, key, aerospike.BinMap{"n": i}))
+	}
+
+	after, err := container.MemoryBreakdown(ctx, "test")
+	require.NoError(t, err)
+	assert.Greater(t, after.IndexBytes, before.IndexBytes)
+}
@@ -0,0 +1,251 @@
+package aerospike
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+const (
+	aerospikeTLSPort = "4333/tcp"
+	tlsDir           = "/etc/aerospike/tls"
+	// defaultTLSName is the TLS name of the certificates generated by WithTLS.
+	defaultTLSName = "aerospike"
+	// tlsCertValidity is how long the certificates generated by WithTLS are
+	// valid for.
+	tlsCertValidity = 7 * 24 * time.Hour
+)
+
+// ErrTLSNotEnabled is returned by the TLS accessors when the container was
+// started without WithTLS or WithTLSCerts.
+var ErrTLSNotEnabled = errors.New("TLS is not enabled")
+
+// tlsMaterial holds the PEM-encoded certificates the server is configured
+// with.
+type tlsMaterial struct {
+	name    string
+	caPEM   []byte
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// WithTLS generates a throwaway certificate authority and a server
+// certificate signed by it, and enables a TLS service port alongside the plain
+// one. The server certificate is issued for the TLS name "aerospike",
+// localhost and 127.0.0.1. Use TLSCACert to trust the authority, TLSPort to
+// reach the port and TLSConfig for a ready tls.Config; NewClient connects over
+// TLS once it is enabled.
+//
+// TLS is an enterprise feature and requires WithEnterpriseEdition.
+func WithTLS() Option {
+	return func(o *options) error {
+		material, err := generateTLSMaterial(defaultTLSName)
+		if err != nil {
+			return err
+		}
+
+		return applyTLS(o, material)
+	}
+}
+
+// WithTLSCerts is like WithTLS but configures the server with the given
+// PEM-encoded CA certificate, server certificate and server key. The TLS name
+// is taken from the server certificate's first DNS name, or its common name
+// when it has none.
+func WithTLSCerts(caPEM, certPEM, keyPEM []byte) Option {
+	return func(o *options) error {
+		if pool := x509.NewCertPool(); !pool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("%w: CA PEM contains no certificate", ErrInvalidOption)
+		}
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("%w: invalid server certificate or key: %w", ErrInvalidOption, err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("%w: invalid server certificate: %w", ErrInvalidOption, err)
+		}
+
+		name := leaf.Subject.CommonName
+		if len(leaf.DNSNames) > 0 {
+			name = leaf.DNSNames[0]
+		}
+		if name == "" {
+			return fmt.Errorf("%w: server certificate has neither a DNS name nor a common name", ErrInvalidOption)
+		}
+
+		return applyTLS(o, &tlsMaterial{name: name, caPEM: caPEM, certPEM: certPEM, keyPEM: keyPEM})
+	}
+}
+
+// TLSCACert returns the PEM-encoded certificate authority that signed the
+// server certificate, or nil when TLS is not enabled.
+func (c Container) TLSCACert() []byte {
+	if c.settings.tls == nil {
+		return nil
+	}
+
+	return c.settings.tls.caPEM
+}
+
+// TLSPort returns the mapped port of the TLS service port.
+func (c Container) TLSPort(ctx context.Context) (int, error) {
+	if c.settings.tls == nil {
+		return 0, ErrTLSNotEnabled
+	}
+
+	return c.mappedPort(ctx, aerospikeTLSPort)
+}
+
+// TLSConfig returns a tls.Config that trusts the server's certificate
+// authority and verifies the server's TLS name.
+func (c Container) TLSConfig() (*tls.Config, error) {
+	if c.settings.tls == nil {
+		return nil, ErrTLSNotEnabled
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(c.settings.tls.caPEM)
+
+	return &tls.Config{
+		RootCAs:    pool,
+		ServerName: c.settings.tls.name,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// tlsHost returns the host to connect a client to over TLS.
+func (c Container) tlsHost(ctx context.Context, clientPolicy *aerospike.ClientPolicy) (*aerospike.Host, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch host: %w", err)
+	}
+	port, err := c.TLSPort(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch TLS port: %w", err)
+	}
+	if clientPolicy.TlsConfig, err = c.TLSConfig(); err != nil {
+		return nil, err
+	}
+
+	tlsHost := aerospike.NewHost(host, port)
+	tlsHost.TLSName = c.settings.tls.name
+
+	return tlsHost, nil
+}
+
+// applyTLS records material in o and adds the configuration edit that
+// enables the TLS service port.
+func applyTLS(o *options, material *tlsMaterial) error {
+	o.tls = material
+	o.configEdits = append(o.configEdits, func(cfg *serverConfig, req *testcontainers.GenericContainerRequest) error {
+		if !isEnterpriseImage(req.Image) {
+			return fmt.Errorf("%w: TLS requires an enterprise image, got %q", ErrInvalidOption, req.Image)
+		}
+
+		files := []struct {
+			name    string
+			content []byte
+		}{
+			{"ca.pem", material.caPEM},
+			{"server.pem", material.certPEM},
+			{"server.key", material.keyPEM},
+		}
+		for _, f := range files {
+			req.Files = append(req.Files, testcontainers.ContainerFile{
+				Reader:            strings.NewReader(string(f.content)),
+				ContainerFilePath: tlsDir + "/" + f.name,
+				FileMode:          0o600,
+			})
+		}
+		req.ExposedPorts = append(req.ExposedPorts, aerospikeTLSPort)
+
+		network := cfg.network()
+		// The tls stanza has to be defined before the service stanza uses it.
+		tlsStanza := &stanza{name: "tls " + material.name}
+		tlsStanza.set("ca-file", tlsDir+"/ca.pem")
+		tlsStanza.set("cert-file", tlsDir+"/server.pem")
+		tlsStanza.set("key-file", tlsDir+"/server.key")
+		network.children = append([]*stanza{tlsStanza}, network.children...)
+
+		service := network.child("service")
+		service.set("tls-address", "any")
+		service.set("tls-port", strings.TrimSuffix(aerospikeTLSPort, "/tcp"))
+		service.set("tls-name", material.name)
+		return nil
+	})
+
+	return nil
+}
+
+// generateTLSMaterial creates a certificate authority and a server
+// certificate for name, localhost and 127.0.0.1 signed by it.
+func generateTLSMaterial(name string) (*tlsMaterial, error) {
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "testcontainers-aerospike CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(tlsCertValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate server key: %w", err)
+	}
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name, "localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(tlsCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create server certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(serverKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode server key: %w", err)
+	}
+
+	return &tlsMaterial{
+		name:    name,
+		caPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
@@ -0,0 +1,112 @@
+package aerospike
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTLSMaterial(t *testing.T) {
+	material, err := generateTLSMaterial("aerospike")
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(material.caPEM))
+
+	block, _ := pem.Decode(material.certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	for _, name := range []string{"aerospike", "localhost", "127.0.0.1"} {
+		_, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots})
+		require.NoErrorf(t, err, "certificate should be valid for %s", name)
+	}
+}
+
+func TestWithTLS(t *testing.T) {
+	req, settings, err := newContainerRequest(WithEnterpriseEdition(), WithTLS())
+	require.NoError(t, err)
+
+	assert.Contains(t, req.ExposedPorts, aerospikeTLSPort)
+	var copied []string
+	for _, f := range req.Files {
+		copied = append(copied, f.ContainerFilePath)
+	}
+	assert.Subset(t, copied, []string{tlsDir + "/ca.pem", tlsDir + "/server.pem", tlsDir + "/server.key"})
+
+	c := Container{settings: settings}
+	assert.Equal(t, settings.tls.caPEM, c.TLSCACert())
+	tlsConfig, err := c.TLSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "aerospike", tlsConfig.ServerName)
+
+	conf := renderedConfig(t, WithEnterpriseEdition(), WithTLS())
+	assert.Contains(t, conf, "network {\n"+
+		"\ttls aerospike {\n"+
+		"\t\tca-file /etc/aerospike/tls/ca.pem\n"+
+		"\t\tcert-file /etc/aerospike/tls/server.pem\n"+
+		"\t\tkey-file /etc/aerospike/tls/server.key\n"+
+		"\t}\n"+
+		"\tservice {\n"+
+		"\t\taddress any\n"+
+		"\t\tport 3000\n"+
+		"\t\ttls-address any\n"+
+		"\t\ttls-port 4333\n"+
+		"\t\ttls-name aerospike\n"+
+		"\t}\n")
+}
+
+func TestWithTLSCerts(t *testing.T) {
+	material, err := generateTLSMaterial("db.internal")
+	require.NoError(t, err)
+
+	_, settings, err := newContainerRequest(WithEnterpriseEdition(), WithTLSCerts(material.caPEM, material.certPEM, material.keyPEM))
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", settings.tls.name)
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithTLSCerts(nil, material.certPEM, material.keyPEM))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithEnterpriseEdition(), WithTLSCerts(material.caPEM, material.certPEM, material.caPEM))
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestWithTLSRequiresEnterprise(t *testing.T) {
+	_, _, err := newContainerRequest(WithTLS())
+	require.ErrorIs(t, err, ErrInvalidOption)
+}
+
+func TestTLSAccessorsWithoutTLS(t *testing.T) {
+	var c Container
+
+	assert.Nil(t, c.TLSCACert())
+	_, err := c.TLSConfig()
+	require.ErrorIs(t, err, ErrTLSNotEnabled)
+	_, err = c.TLSPort(context.Background())
+	require.ErrorIs(t, err, ErrTLSNotEnabled)
+}
+
+func TestWithTLSConnects(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithEnterpriseEdition(), WithTLS())
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "tls", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
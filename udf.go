@@ -0,0 +1,132 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// udfRegisterTimeout bounds how long RegisterUDF waits for a module to be
+// listed after it has been registered.
+const udfRegisterTimeout = 30 * time.Second
+
+// RegisterUDF registers luaSource as the Lua module moduleName, such as
+// "counters" or "counters.lua", and waits until the server lists it in
+// udf-list. Functions in the module can then be called through the client as
+// moduleName without the ".lua" suffix.
+func (c Container) RegisterUDF(ctx context.Context, moduleName string, luaSource []byte) error {
+	moduleName = strings.TrimSuffix(strings.TrimSpace(moduleName), ".lua")
+	if moduleName == "" || strings.ContainsAny(moduleName, "/;,=") {
+		return fmt.Errorf("%w: invalid UDF module name %q", ErrInvalidArgument, moduleName)
+	}
+	if len(luaSource) == 0 {
+		return fmt.Errorf("%w: UDF module %q has no source", ErrInvalidArgument, moduleName)
+	}
+	filename := moduleName + ".lua"
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy := aerospike.NewWritePolicy(0, 0)
+	if err := applyDeadline(ctx, &policy.BasePolicy); err != nil {
+		return err
+	}
+
+	task, aerr := client.RegisterUDF(policy, luaSource, filename, aerospike.LUA)
+	if aerr != nil {
+		return fmt.Errorf("failed to register UDF module %s: %w", filename, aerr)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("registration of UDF module %s did not complete: %w", filename, ctx.Err())
+	case aerr := <-task.OnComplete():
+		if aerr != nil {
+			return fmt.Errorf("registration of UDF module %s failed: %w", filename, aerr)
+		}
+	}
+
+	err = pollUntil(ctx, udfRegisterTimeout, func(ctx context.Context) (bool, error) {
+		resp, err := c.AsInfo(ctx, "udf-list")
+		if err != nil {
+			return false, err
+		}
+		return hasUDFModule(resp, filename), nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for UDF module %s to be listed", err, filename)
+	}
+
+	return err
+}
+
+// WithUDFDir registers every *.lua file in localDir as a UDF module named after
+// the file once the server is ready, the way deployments ship a bundle of
+// modules, so RunContainer only returns once each module is listed in
+// udf-list. Subdirectories are not searched. The files are read when the
+// option is applied, so a missing directory or one without Lua files is
+// reported before any container is created.
+func WithUDFDir(localDir string) Option {
+	return func(o *options) error {
+		entries, err := os.ReadDir(localDir)
+		if err != nil {
+			return fmt.Errorf("%w: failed to read UDF directory: %w", ErrInvalidOption, err)
+		}
+
+		found := false
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".lua" {
+				continue
+			}
+			source, err := os.ReadFile(filepath.Join(localDir, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("%w: failed to read UDF module: %w", ErrInvalidOption, err)
+			}
+			o.udfs = append(o.udfs, schemaUDF{moduleName: entry.Name(), luaSource: source})
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("%w: UDF directory %q has no .lua files", ErrInvalidOption, localDir)
+		}
+
+		return nil
+	}
+}
+
+// hasUDFModule reports whether a udf-list response, such as
+// "filename=a.lua,hash=...,type=LUA;", lists filename.
+func hasUDFModule(resp, filename string) bool {
+	for _, entry := range strings.Split(resp, ";") {
+		if parseInfoPairs(entry, ",")["filename"] == filename {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attachUDFs adds the hook that registers the modules set with WithUDFDir
+// once the server is ready. It is attached after the hooks of the other
+// options, so the modules are registered after security has been set up.
+func attachUDFs(req *testcontainers.GenericContainerRequest, settings options) {
+	req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+		PostReadies: []testcontainers.ContainerHook{
+			func(ctx context.Context, c testcontainers.Container) error {
+				schema := &SchemaBuilder{udfs: settings.udfs}
+				container := Container{Container: c, settings: settings, client: &sharedClient{}}
+				defer container.client.close()
+
+				return schema.Apply(ctx, container)
+			},
+		},
+	})
+}
@@ -0,0 +1,111 @@
+package aerospike
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasUDFModule(t *testing.T) {
+	resp := "filename=greet.lua,hash=0a1b2c,type=LUA;filename=counters.lua,hash=3d4e5f,type=LUA;"
+
+	assert.True(t, hasUDFModule(resp, "greet.lua"))
+	assert.True(t, hasUDFModule(resp, "counters.lua"))
+	assert.False(t, hasUDFModule(resp, "count.lua"))
+	assert.False(t, hasUDFModule("", "greet.lua"))
+}
+
+func TestRegisterUDFRejectsInvalidArguments(t *testing.T) {
+	var c Container
+
+	require.ErrorIs(t, c.RegisterUDF(context.Background(), " ", []byte("return 1")), ErrInvalidArgument)
+	require.ErrorIs(t, c.RegisterUDF(context.Background(), "dir/greet", []byte("return 1")), ErrInvalidArgument)
+	require.ErrorIs(t, c.RegisterUDF(context.Background(), "greet", nil), ErrInvalidArgument)
+}
+
+func TestRegisterUDF(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	source := []byte("function hello(rec, name)\n  return 'hello ' .. name\nend\n")
+	require.NoError(t, container.RegisterUDF(ctx, "greet.lua", source))
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("test", "udf", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+
+	result, err := client.Execute(nil, key, "greet", "hello", aerospike.NewValue("world"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", result)
+}
+
+// writeUDFDir writes two Lua modules and an unrelated file to a temporary
+// directory and returns its path.
+func writeUDFDir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"greet.lua":    "function hello(rec, name)\n  return 'hello ' .. name\nend\n",
+		"counters.lua": "function bump(rec, bin)\n  rec[bin] = (rec[bin] or 0) + 1\n  aerospike:update(rec)\n  return rec[bin]\nend\n",
+		"README.md":    "not a module\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func TestWithUDFDir(t *testing.T) {
+	req, settings, err := newContainerRequest(WithUDFDir(writeUDFDir(t)))
+	require.NoError(t, err)
+
+	require.Len(t, settings.udfs, 2)
+	assert.Equal(t, "counters.lua", settings.udfs[0].moduleName)
+	assert.Equal(t, "greet.lua", settings.udfs[1].moduleName)
+
+	require.Len(t, req.LifecycleHooks, 1)
+	assert.Len(t, req.LifecycleHooks[0].PostReadies, 1)
+}
+
+func TestWithUDFDirValidation(t *testing.T) {
+	_, _, err := newContainerRequest(WithUDFDir(filepath.Join(t.TempDir(), "missing")))
+	require.ErrorIs(t, err, ErrInvalidOption)
+
+	_, _, err = newContainerRequest(WithUDFDir(t.TempDir()))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "no .lua files")
+}
+
+func TestWithUDFDirRegistersModules(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithUDFDir(writeUDFDir(t)))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	resp, err := container.AsInfo(ctx, "udf-list")
+	require.NoError(t, err)
+	assert.True(t, hasUDFModule(resp, "greet.lua"))
+	assert.True(t, hasUDFModule(resp, "counters.lua"))
+	assert.False(t, hasUDFModule(resp, "README.md"))
+}
@@ -2,6 +2,7 @@ package aerospike
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,19 +16,44 @@ const (
 	defaultPollInterval   = 100 * time.Millisecond
 )
 
-type aerospikeWaitStrategy struct{}
+type aerospikeWaitStrategy struct {
+	// timeout bounds the whole wait; zero selects defaultStartupTimeout.
+	timeout time.Duration
+	// namespace is checked to be ready for writes; empty skips the check.
+	namespace string
+	// user and password authenticate the readiness checks when security is
+	// enabled. When they are rejected, the checks log in with fallbackUser and
+	// fallbackPassword instead, as on the first start, before the user set
+	// with WithSecurity has been provisioned.
+	user             string
+	password         string
+	fallbackUser     string
+	fallbackPassword string
+	// port is the service port to probe; empty selects aerospikeServicePort.
+	port string
+}
 
 var _ wait.Strategy = (*aerospikeWaitStrategy)(nil)
 
 func newAerospikeWaitStrategy() aerospikeWaitStrategy {
-	return aerospikeWaitStrategy{}
+	return aerospikeWaitStrategy{namespace: defaultNamespace}
 }
 
 func (s aerospikeWaitStrategy) WaitUntilReady(ctx context.Context, target wait.StrategyTarget) error {
-	ctx, cancel := context.WithTimeout(ctx, defaultStartupTimeout)
+	timeout := s.timeout
+	if timeout == 0 {
+		timeout = defaultStartupTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	if err := wait.NewHostPortStrategy(aerospikeServicePort).WaitUntilReady(ctx, target); err != nil {
+	servicePort := s.port
+	if servicePort == "" {
+		servicePort = aerospikeServicePort
+	}
+
+	portStrategy := wait.NewHostPortStrategy(servicePort).WithStartupTimeout(timeout)
+	if err := portStrategy.WaitUntilReady(ctx, target); err != nil {
 		return fmt.Errorf("error waiting for port to open: %w", err)
 	}
 
@@ -35,7 +61,7 @@ func (s aerospikeWaitStrategy) WaitUntilReady(ctx context.Context, target wait.S
 	if err != nil {
 		return fmt.Errorf("failed to fetch host: %w", err)
 	}
-	port, err := target.MappedPort(ctx, aerospikeServicePort)
+	port, err := target.MappedPort(ctx, servicePort)
 	if err != nil {
 		return fmt.Errorf("failed to fetch port: %w", err)
 	}
@@ -62,13 +88,10 @@ func (s aerospikeWaitStrategy) pollUntilReady(ctx context.Context, host string,
 func (s aerospikeWaitStrategy) isReady(host string, port int) (bool, error) {
 	// This is similar to the implementation in testcontainers-spring-boot:
 	// https://github.com/PlaytikaOSS/testcontainers-spring-boot/blob/0c007f0b774eaed595e029c94e812a30fe2d1a6b/embedded-aerospike/src/main/java/com/playtika/testcontainer/aerospike/AerospikeWaitStrategy.java#L23
-	clientPolicy := aerospike.NewClientPolicy()
-	// Set a short timeout for readiness checks to fail fast
-	clientPolicy.Timeout = 2 * time.Second
-
-	client, err := aerospike.NewClientWithPolicy(clientPolicy, host, port)
+	client, err := s.connect(host, port)
 	if err != nil {
-		if err.Matches(types.INVALID_NODE_ERROR) {
+		// Logins fail with the latter codes while the security subsystem starts.
+		if err.Matches(types.INVALID_NODE_ERROR, types.NOT_AUTHENTICATED, types.SECURITY_NOT_ENABLED) {
 			return false, nil
 		}
 		return false, fmt.Errorf("failed to connect to Aerospike: %w", err)
@@ -93,5 +116,185 @@ func (s aerospikeWaitStrategy) isReady(host string, port int) (bool, error) {
 		}
 	}
 
+	// The service can accept connections before the namespace accepts writes.
+	if s.namespace == "" {
+		return true, nil
+	}
+	command := "namespace/" + s.namespace
+	for _, node := range nodes {
+		info, err := node.RequestInfo(nil, command)
+		if err != nil || !isNamespaceReady(info[command]) {
+			return false, nil
+		}
+	}
+
 	return true, nil
 }
+
+// connect logs in to the server for a readiness check, with the fallback
+// credentials when the primary ones are rejected.
+func (s aerospikeWaitStrategy) connect(host string, port int) (*aerospike.Client, aerospike.Error) {
+	clientPolicy := aerospike.NewClientPolicy()
+	// Set a short timeout for readiness checks to fail fast
+	clientPolicy.Timeout = 2 * time.Second
+	clientPolicy.User = s.user
+	clientPolicy.Password = s.password
+
+	client, err := aerospike.NewClientWithPolicy(clientPolicy, host, port)
+	if err == nil || s.fallbackUser == "" || !isLoginRejected(err) {
+		return client, err
+	}
+
+	clientPolicy.User = s.fallbackUser
+	clientPolicy.Password = s.fallbackPassword
+	return aerospike.NewClientWithPolicy(clientPolicy, host, port)
+}
+
+// isLoginRejected reports whether err means the server turned the
+// credentials down, rather than that it could not be reached.
+func isLoginRejected(err aerospike.Error) bool {
+	return err.Matches(types.INVALID_USER, types.INVALID_PASSWORD, types.INVALID_CREDENTIAL, types.NOT_AUTHENTICATED)
+}
+
+// isNamespaceReady reports whether a "namespace/<ns>" info response describes
+// a namespace that accepts writes.
+func isNamespaceReady(resp string) bool {
+	if !isNamespaceInfo(resp) {
+		return false
+	}
+
+	return parseInfoPairs(resp, ";")["stop_writes"] != "true"
+}
+
+// ErrWaitTimeout is returned when a Container wait helper gives up before its
+// condition is met.
+var ErrWaitTimeout = errors.New("timed out waiting")
+
+// WaitForStableCluster waits until the node sees a cluster of exactly size
+// nodes with no partitions left to migrate, the usual condition to wait for
+// after a node joins or leaves. On timeout the error says which condition was
+// not met and the last value observed for it.
+func (c Container) WaitForStableCluster(ctx context.Context, size int, timeout time.Duration) error {
+	if size < 1 {
+		return fmt.Errorf("%w: cluster size must be at least 1, got %d", ErrInvalidArgument, size)
+	}
+
+	var clusterSize, remaining int64 = -1, -1
+	err := pollUntil(ctx, timeout, func(ctx context.Context) (bool, error) {
+		stats, err := c.Stats(ctx)
+		if err != nil {
+			return false, err
+		}
+		if clusterSize, err = statInt(stats, "cluster_size"); err != nil {
+			return false, err
+		}
+		if remaining, err = statInt(stats, "migrate_partitions_remaining"); err != nil {
+			return false, err
+		}
+		return clusterSize == int64(size) && remaining == 0, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		if clusterSize != int64(size) {
+			return fmt.Errorf("%w for cluster size %d: last observed cluster_size=%d", err, size, clusterSize)
+		}
+		return fmt.Errorf("%w for migrations to finish: last observed migrate_partitions_remaining=%d", err, remaining)
+	}
+
+	return err
+}
+
+// WaitForClusterSize waits until the node sees a cluster of exactly size
+// nodes, for bringing a cluster up one node at a time. Unlike
+// WaitForStableCluster it does not wait for migrations to finish. On timeout
+// the error includes the last observed size.
+func (c Container) WaitForClusterSize(ctx context.Context, size int, timeout time.Duration) error {
+	if size < 1 {
+		return fmt.Errorf("%w: cluster size must be at least 1, got %d", ErrInvalidArgument, size)
+	}
+
+	var clusterSize int64 = -1
+	err := pollUntil(ctx, timeout, func(ctx context.Context) (bool, error) {
+		stats, err := c.Stats(ctx)
+		if err != nil {
+			return false, err
+		}
+		if clusterSize, err = statInt(stats, "cluster_size"); err != nil {
+			return false, err
+		}
+		return clusterSize == int64(size), nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for cluster size %d: last observed cluster_size=%d", err, size, clusterSize)
+	}
+
+	return err
+}
+
+// WaitForMigrations waits until the node has no partitions left to migrate in
+// any namespace, so records are balanced after a node joins, leaves or
+// restarts. On timeout the error includes the last observed count.
+func (c Container) WaitForMigrations(ctx context.Context, timeout time.Duration) error {
+	var remaining int64 = -1
+	err := pollUntil(ctx, timeout, func(ctx context.Context) (bool, error) {
+		stats, err := c.Stats(ctx)
+		if err != nil {
+			return false, err
+		}
+		if remaining, err = statInt(stats, "migrate_partitions_remaining"); err != nil {
+			return false, err
+		}
+		return remaining == 0, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for migrations to finish: last observed migrate_partitions_remaining=%d", err, remaining)
+	}
+
+	return err
+}
+
+// IsReady reports whether the server answers the "status" info command with
+// "ok", a lightweight probe for the middle of a test, such as after a
+// configuration change. It returns false with a nil error when the server
+// answers but is not ready, ErrContainerNotRunning when the container is not
+// running, and ErrInfoCommandFailed when asinfo cannot reach the server.
+func (c Container) IsReady(ctx context.Context) (bool, error) {
+	if err := c.requireRunning(ctx); err != nil {
+		return false, err
+	}
+
+	status, err := c.AsInfo(ctx, "status")
+	if err != nil {
+		return false, err
+	}
+
+	return status == "ok", nil
+}
+
+// pollUntil calls check every defaultPollInterval until it reports done,
+// returns an error, or timeout elapses, in which case ErrWaitTimeout is
+// returned. Cancelling ctx stops polling with the context's error.
+func pollUntil(ctx context.Context, timeout time.Duration, check func(ctx context.Context) (bool, error)) error {
+	if timeout <= 0 {
+		return fmt.Errorf("%w: timeout must be positive, got %s", ErrInvalidArgument, timeout)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s", ErrWaitTimeout, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(defaultPollInterval):
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package aerospike
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/moby/moby/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// statsContainer returns a Container whose "statistics" responses are taken
+// from responses in turn, repeating the last one once they run out.
+func statsContainer(responses ...string) Container {
+	calls := 0
+	return Container{
+		Container: &fakeContainer{exec: func(context.Context, []string) (int, string, error) {
+			resp := responses[min(calls, len(responses)-1)]
+			calls++
+			return 0, resp, nil
+		}},
+		settings: defaultOptions(),
+	}
+}
+
+func TestWaitForStableCluster(t *testing.T) {
+	c := statsContainer(
+		"cluster_size=1;migrate_partitions_remaining=0",
+		"cluster_size=2;migrate_partitions_remaining=812",
+		"cluster_size=2;migrate_partitions_remaining=0",
+	)
+
+	require.NoError(t, c.WaitForStableCluster(context.Background(), 2, 5*time.Second))
+}
+
+func TestWaitForStableClusterReportsSizeTimeout(t *testing.T) {
+	c := statsContainer("cluster_size=1;migrate_partitions_remaining=0")
+
+	err := c.WaitForStableCluster(context.Background(), 2, 300*time.Millisecond)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	assert.Contains(t, err.Error(), "cluster_size=1")
+}
+
+func TestWaitForStableClusterReportsMigrationTimeout(t *testing.T) {
+	c := statsContainer("cluster_size=2;migrate_partitions_remaining=17")
+
+	err := c.WaitForStableCluster(context.Background(), 2, 300*time.Millisecond)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	assert.Contains(t, err.Error(), "migrate_partitions_remaining=17")
+}
+
+func TestWaitForStableClusterValidatesArguments(t *testing.T) {
+	c := statsContainer("cluster_size=1;migrate_partitions_remaining=0")
+
+	require.ErrorIs(t, c.WaitForStableCluster(context.Background(), 0, time.Second), ErrInvalidArgument)
+	require.ErrorIs(t, c.WaitForStableCluster(context.Background(), 1, 0), ErrInvalidArgument)
+}
+
+func TestWaitForClusterSize(t *testing.T) {
+	c := statsContainer(
+		"cluster_size=1;migrate_partitions_remaining=0",
+		"cluster_size=3;migrate_partitions_remaining=2048",
+	)
+
+	require.NoError(t, c.WaitForClusterSize(context.Background(), 3, 5*time.Second))
+}
+
+func TestWaitForClusterSizeReportsTimeout(t *testing.T) {
+	c := statsContainer("cluster_size=2;migrate_partitions_remaining=0")
+
+	err := c.WaitForClusterSize(context.Background(), 3, 300*time.Millisecond)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	assert.Contains(t, err.Error(), "cluster_size=2")
+
+	require.ErrorIs(t, c.WaitForClusterSize(context.Background(), 0, time.Second), ErrInvalidArgument)
+}
+
+func TestWaitForMigrations(t *testing.T) {
+	c := statsContainer(
+		"migrate_partitions_remaining=812",
+		"migrate_partitions_remaining=40",
+		"migrate_partitions_remaining=0",
+	)
+
+	require.NoError(t, c.WaitForMigrations(context.Background(), 5*time.Second))
+}
+
+func TestWaitForMigrationsReportsTimeout(t *testing.T) {
+	c := statsContainer("migrate_partitions_remaining=17")
+
+	err := c.WaitForMigrations(context.Background(), 300*time.Millisecond)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	assert.Contains(t, err.Error(), "migrate_partitions_remaining=17")
+}
+
+func TestWaitForMigrationsHonorsContext(t *testing.T) {
+	c := statsContainer("migrate_partitions_remaining=17")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	err := c.WaitForMigrations(ctx, time.Minute)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestIsNamespaceReady(t *testing.T) {
+	assert.True(t, isNamespaceReady("objects=0;stop_writes=false;replication-factor=1"))
+	assert.False(t, isNamespaceReady("objects=0;stop_writes=true"))
+	assert.False(t, isNamespaceReady("type=unknown"))
+	assert.False(t, isNamespaceReady("ERROR::namespace not found"))
+	assert.False(t, isNamespaceReady(""))
+}
+
+func TestWaitStrategyChecksConfiguredNamespace(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []testcontainers.ContainerCustomizer
+		want string
+	}{
+		{name: "default", want: "test"},
+		{name: "WithNamespace", opts: []testcontainers.ContainerCustomizer{WithNamespace("custom")}, want: "custom"},
+		{name: "WithNamespace and rendered config", opts: []testcontainers.ContainerCustomizer{WithNamespace("custom"), WithReplicationFactor("custom", 2)}, want: "custom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _, err := newContainerRequest(tt.opts...)
+			require.NoError(t, err)
+
+			strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, strategy.namespace)
+		})
+	}
+}
+
+func TestWaitStrategySkipsNamespaceWithConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aerospike.conf")
+	require.NoError(t, os.WriteFile(path, []byte(minimalConfig), 0o600))
+
+	req, _, err := newContainerRequest(WithConfigFile(path))
+	require.NoError(t, err)
+
+	strategy, ok := req.WaitingFor.(aerospikeWaitStrategy)
+	require.True(t, ok)
+	assert.Empty(t, strategy.namespace)
+}
+
+func TestFirstWriteSucceedsImmediately(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	container := startContainer(ctx, t, WithNamespace("custom"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, container.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	client, err := container.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	key, err := aerospike.NewKey("custom", "first-write", "key")
+	require.NoError(t, err)
+	require.NoError(t, client.Put(nil, key, aerospike.BinMap{"bin": "value"}))
+}
+
+func TestIsReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		fake    *fakeContainer
+		want    bool
+		wantErr error
+	}{
+		{
+			name: "ok",
+			fake: &fakeContainer{exec: func(context.Context, []string) (int, string, error) { return 0, "ok", nil }},
+			want: true,
+		},
+		{
+			name: "not ready",
+			fake: &fakeContainer{exec: func(context.Context, []string) (int, string, error) { return 0, "", nil }},
+		},
+		{
+			name:    "info command failed",
+			fake:    &fakeContainer{exec: func(context.Context, []string) (int, string, error) { return 1, "", nil }, stderr: "Failed to connect"},
+			wantErr: ErrInfoCommandFailed,
+		},
+		{
+			name:    "not running",
+			fake:    &fakeContainer{state: &container.State{Status: container.StateExited}},
+			wantErr: ErrContainerNotRunning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Container{Container: tt.fake, settings: defaultOptions()}
+
+			ready, err := c.IsReady(context.Background())
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, ready)
+		})
+	}
+}
+
+func TestIsReadyOnRunningServer(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	c := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, c.Terminate(ctx), "failed to terminate Aerospike container")
+	})
+
+	ready, err := c.IsReady(ctx)
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	stopTimeout := 10 * time.Second
+	require.NoError(t, c.Stop(ctx, &stopTimeout))
+
+	_, err = c.IsReady(ctx)
+	require.ErrorIs(t, err, ErrContainerNotRunning)
+}
@@ -0,0 +1,161 @@
+package aerospike
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// WithXDRDestination defines the XDR datacenter dc, pointing at the running
+// container seed, and ships writes to namespaces there once the server is
+// ready. The datacenter is created with dynamic set-config commands, so seed
+// only has to be running by the time this container starts, and both
+// containers must share a Docker network, as the default bridge network does.
+// seed must serve the namespaces and must not have security enabled. Use
+// WaitForXDRLag to wait until shipped writes have arrived.
+//
+// XDR is an enterprise feature: it requires WithEnterpriseEdition and,
+// outside the single-node evaluation mode, a feature key that enables it (see
+// WithFeatureKeyFile).
+func WithXDRDestination(dc string, seed Container, namespaces ...string) Option {
+	return func(o *options) error {
+		if dc = strings.TrimSpace(dc); dc == "" {
+			return fmt.Errorf("%w: XDR datacenter is empty", ErrInvalidOption)
+		}
+		if seed.Container == nil {
+			return fmt.Errorf("%w: XDR seed container for datacenter %q is not running", ErrInvalidOption, dc)
+		}
+		if len(namespaces) == 0 {
+			return fmt.Errorf("%w: no namespaces to ship to XDR datacenter %q", ErrInvalidOption, dc)
+		}
+		shipped := make([]string, 0, len(namespaces))
+		for _, namespace := range namespaces {
+			namespace, err := normalizeNamespace(namespace, ErrInvalidOption)
+			if err != nil {
+				return err
+			}
+			shipped = append(shipped, namespace)
+		}
+
+		o.configEdits = append(o.configEdits, func(_ *serverConfig, req *testcontainers.GenericContainerRequest) error {
+			if !isEnterpriseImage(req.Image) {
+				return fmt.Errorf("%w: XDR requires an enterprise image, got %q", ErrInvalidOption, req.Image)
+			}
+
+			req.LifecycleHooks = append(req.LifecycleHooks, testcontainers.ContainerLifecycleHooks{
+				PostReadies: []testcontainers.ContainerHook{
+					func(ctx context.Context, c testcontainers.Container) error {
+						// o holds every option by the time the hook runs.
+						return setupXDRDestination(ctx, c, *o, dc, seed, shipped)
+					},
+				},
+			})
+			return nil
+		})
+
+		return nil
+	}
+}
+
+// WaitForXDRLag blocks until the XDR datacenter dc has caught up: nothing is
+// queued or in flight and the reported lag is zero. This makes tests that read
+// shipped records from the destination deterministic. It fails with
+// ErrWaitTimeout, reporting the last observed statistics, once timeout
+// elapses.
+func (c Container) WaitForXDRLag(ctx context.Context, dc string, timeout time.Duration) error {
+	if dc = strings.TrimSpace(dc); dc == "" {
+		return fmt.Errorf("%w: datacenter is empty", ErrInvalidArgument)
+	}
+
+	var lag, queued, inProgress int64 = -1, -1, -1
+	err := pollUntil(ctx, timeout, func(ctx context.Context) (bool, error) {
+		resp, err := c.AsInfo(ctx, "get-stats:context=xdr;dc="+dc)
+		if err != nil {
+			return false, err
+		}
+		if resp == "" || strings.HasPrefix(resp, "ERROR") {
+			return false, fmt.Errorf("%w: XDR statistics for datacenter %q: %s", ErrUnexpectedInfoResponse, dc, resp)
+		}
+
+		stats := parseInfoPairs(resp, ";")
+		if lag, err = statInt(stats, "lag"); err != nil {
+			return false, err
+		}
+		if queued, err = statInt(stats, "in_queue"); err != nil {
+			return false, err
+		}
+		if inProgress, err = statInt(stats, "in_progress"); err != nil {
+			return false, err
+		}
+		return lag == 0 && queued == 0 && inProgress == 0, nil
+	})
+	if errors.Is(err, ErrWaitTimeout) {
+		return fmt.Errorf("%w for XDR datacenter %q to catch up: last observed lag=%d in_queue=%d in_progress=%d", err, dc, lag, queued, inProgress)
+	}
+
+	return err
+}
+
+// SetXDRFilter sets the expression that decides which records of namespace
+// are shipped to the XDR datacenter dc; records that do not match are not
+// replicated. A nil filter removes any existing filter so every record ships
+// again.
+//
+// XDR is an enterprise feature, so this only works on the enterprise edition
+// with dc already configured as an XDR destination.
+func (c Container) SetXDRFilter(ctx context.Context, dc, namespace string, filter *aerospike.Expression) error {
+	if dc = strings.TrimSpace(dc); dc == "" {
+		return fmt.Errorf("%w: datacenter is empty", ErrInvalidArgument)
+	}
+	namespace, err := normalizeNamespace(namespace, ErrInvalidArgument)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	if aerr := client.SetXDRFilter(nil, dc, namespace, filter); aerr != nil {
+		return fmt.Errorf("failed to set XDR filter for datacenter %q namespace %q: %w", dc, namespace, aerr)
+	}
+
+	return nil
+}
+
+// setupXDRDestination creates the XDR datacenter dc in c, points it at the
+// service port of seed and ships namespaces to it.
+func setupXDRDestination(ctx context.Context, c testcontainers.Container, settings options, dc string, seed Container, namespaces []string) error {
+	ip, err := seed.ContainerIP(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch address of XDR seed for datacenter %q: %w", dc, err)
+	}
+	address := ip + ":" + strconv.Itoa(seed.settings.serviceContainerPort())
+
+	commands := []string{
+		"set-config:context=xdr;dc=" + dc + ";action=create",
+		"set-config:context=xdr;dc=" + dc + ";node-address-port=" + address + ";action=add",
+	}
+	for _, namespace := range namespaces {
+		commands = append(commands, "set-config:context=xdr;dc="+dc+";namespace="+namespace+";action=add")
+	}
+
+	for _, command := range commands {
+		resp, err := execInfo(ctx, c, settings, command)
+		if err != nil {
+			return err
+		}
+		if resp != "ok" {
+			return fmt.Errorf("%w: %s: %s", ErrConfigRejected, command, resp)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package aerospike
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsv-blockchain/aerospike-client-go/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetXDRFilterRequiresDatacenterAndNamespace(t *testing.T) {
+	// Arguments are validated before the container is contacted.
+	c := Container{}
+
+	require.ErrorIs(t, c.SetXDRFilter(context.Background(), "", "test", nil), ErrInvalidArgument)
+	require.ErrorIs(t, c.SetXDRFilter(context.Background(), "dc2", "", nil), ErrInvalidArgument)
+}
+
+func TestWithXDRDestinationValidation(t *testing.T) {
+	seed := Container{Container: &fakeContainer{}, settings: defaultOptions()}
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "empty datacenter", opt: WithXDRDestination(" ", seed, "test")},
+		{name: "no seed", opt: WithXDRDestination("dc2", Container{}, "test")},
+		{name: "no namespaces", opt: WithXDRDestination("dc2", seed)},
+		{name: "empty namespace", opt: WithXDRDestination("dc2", seed, "test", " ")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := newContainerRequest(WithEnterpriseEdition(), tt.opt)
+			require.ErrorIs(t, err, ErrInvalidOption)
+		})
+	}
+}
+
+func TestWithXDRDestinationRequiresEnterprise(t *testing.T) {
+	seed := Container{Container: &fakeContainer{}, settings: defaultOptions()}
+
+	_, _, err := newContainerRequest(WithXDRDestination("dc2", seed, "test"))
+	require.ErrorIs(t, err, ErrInvalidOption)
+	assert.Contains(t, err.Error(), "enterprise")
+}
+
+func TestWithXDRDestinationConfiguresDatacenter(t *testing.T) {
+	seed := Container{Container: &fakeContainer{ip: "172.17.0.3"}, settings: defaultOptions()}
+
+	req, _, err := newContainerRequest(WithEnterpriseEdition(), WithXDRDestination("dc2", seed, "test", "cache"))
+	require.NoError(t, err)
+	require.Len(t, req.LifecycleHooks, 1)
+	require.Len(t, req.LifecycleHooks[0].PostReadies, 1)
+
+	var commands []string
+	source := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		commands = append(commands, cmd[len(cmd)-1])
+		return 0, "ok", nil
+	}}
+	require.NoError(t, req.LifecycleHooks[0].PostReadies[0](context.Background(), source))
+	assert.Equal(t, []string{
+		"set-config:context=xdr;dc=dc2;action=create",
+		"set-config:context=xdr;dc=dc2;node-address-port=172.17.0.3:3000;action=add",
+		"set-config:context=xdr;dc=dc2;namespace=test;action=add",
+		"set-config:context=xdr;dc=dc2;namespace=cache;action=add",
+	}, commands)
+}
+
+func TestSetupXDRDestinationReportsRejection(t *testing.T) {
+	seed := Container{Container: &fakeContainer{ip: "172.17.0.3"}, settings: defaultOptions()}
+	source := &fakeContainer{exec: func(_ context.Context, cmd []string) (int, string, error) {
+		if strings.Contains(cmd[len(cmd)-1], "namespace=") {
+			return 0, "ERROR::namespace not found", nil
+		}
+		return 0, "ok", nil
+	}}
+
+	err := setupXDRDestination(context.Background(), source, defaultOptions(), "dc2", seed, []string{"missing"})
+	require.ErrorIs(t, err, ErrConfigRejected)
+	assert.Contains(t, err.Error(), "namespace not found")
+}
+
+func TestWaitForXDRLag(t *testing.T) {
+	c := statsContainer(
+		"lag=3;in_queue=120;in_progress=4",
+		"lag=0;in_queue=0;in_progress=2",
+		"lag=0;in_queue=0;in_progress=0",
+	)
+
+	require.NoError(t, c.WaitForXDRLag(context.Background(), "dc2", 5*time.Second))
+}
+
+func TestWaitForXDRLagReportsTimeout(t *testing.T) {
+	c := statsContainer("lag=2;in_queue=17;in_progress=0")
+
+	err := c.WaitForXDRLag(context.Background(), "dc2", 300*time.Millisecond)
+	require.ErrorIs(t, err, ErrWaitTimeout)
+	assert.Contains(t, err.Error(), "in_queue=17")
+
+	require.ErrorIs(t, c.WaitForXDRLag(context.Background(), "", time.Second), ErrInvalidArgument)
+}
+
+func TestWaitForXDRLagReportsUnknownDatacenter(t *testing.T) {
+	c := statsContainer("ERROR::DC not found")
+
+	err := c.WaitForXDRLag(context.Background(), "dc2", time.Second)
+	require.ErrorIs(t, err, ErrUnexpectedInfoResponse)
+}
+
+func TestWithXDRDestinationShipsRecords(t *testing.T) {
+	skipIfDockerNotAvailable(t)
+
+	ctx := context.Background()
+
+	destination := startContainer(ctx, t)
+	t.Cleanup(func() {
+		require.NoErrorf(t, destination.Terminate(ctx), "failed to terminate destination container")
+	})
+
+	source := startContainer(ctx, t, WithEnterpriseEdition(), WithXDRDestination("dc2", *destination, "test"))
+	t.Cleanup(func() {
+		require.NoErrorf(t, source.Terminate(ctx), "failed to terminate source container")
+	})
+
+	sourceClient, err := source.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(sourceClient.Close)
+
+	key, err := aerospike.NewKey("test", "xdr", "key")
+	require.NoError(t, err)
+	require.NoError(t, sourceClient.Put(nil, key, aerospike.BinMap{"bin": "shipped"}))
+
+	require.NoError(t, source.WaitForXDRLag(ctx, "dc2", 30*time.Second))
+
+	destinationClient, err := destination.NewClient(ctx)
+	require.NoError(t, err)
+	t.Cleanup(destinationClient.Close)
+
+	record, err := destinationClient.Get(nil, key)
+	require.NoError(t, err)
+	assert.Equal(t, "shipped", record.Bins["bin"])
+}